@@ -2,15 +2,20 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/Bharath-code/git-scope/internal/browser"
 	"github.com/Bharath-code/git-scope/internal/config"
+	"github.com/Bharath-code/git-scope/internal/gitstatus"
+	"github.com/Bharath-code/git-scope/internal/model"
 	"github.com/Bharath-code/git-scope/internal/scan"
 	"github.com/Bharath-code/git-scope/internal/tui"
 )
@@ -31,8 +36,11 @@ Usage:
 
 Commands:
   (default)   Launch TUI dashboard
-  scan        Scan and print repos (JSON)
+  scan        Scan and print repos (--format, --fields, filters; JSON by default)
   scan-all    Full system scan from home directory (with stats)
+  stashes     List stashes across all repos (--format table|json)
+  unpushed    List local branches not on any remote (--format table|json)
+  watch       Print repos, then print them again as they change (--format, --fields)
   init        Create config file interactively
   issue       Open git-scope GitHub issues page in browser
   help        Show this help
@@ -41,7 +49,13 @@ Examples:
   git-scope                    # Scan configured dirs or current dir
   git-scope ~/code ~/work      # Scan specific directories
   git-scope scan .             # Scan current directory (JSON)
+  git-scope scan --format table --fields name,branch,dirty ~/code
+  git-scope scan --ahead --exit-code    # Exit 1 if anything is unpushed
+  git-scope scan --explain ~/code/old   # Why is this directory skipped?
   git-scope scan-all           # Find ALL repos on your system
+  git-scope stashes ~/code     # List every stash under ~/code
+  git-scope unpushed           # Anything that only exists on this machine?
+  git-scope watch --format ndjson ~/code   # Stream status changes
   git-scope init               # Setup config interactively
   git-scope issue              # Open GitHub issues page
 
@@ -75,6 +89,12 @@ func main() {
 	}
 
 	if err := run(cmd, dirs, opts.ConfigPath); err != nil {
+		if errors.Is(err, errReposMatched) {
+			os.Exit(1)
+		}
+		if errors.Is(err, errReposFailed) {
+			os.Exit(2)
+		}
 		log.Fatal(err)
 	}
 }
@@ -112,16 +132,106 @@ func parseCommand(args []string) (cmd string, dirs []string) {
 	}
 
 	switch args[0] {
-	case "scan", "tui", "help", "init", "scan-all", "issue":
+	case "scan", "tui", "help", "init", "scan-all", "issue", "stashes", "unpushed", "watch":
 		return args[0], args[1:]
 	default:
 		return "tui", args // assume it's a directory
 	}
 }
 
+// commandFlags holds flags that belong to a specific subcommand and are
+// given after the subcommand name (e.g. `git-scope stashes --format json`)
+type commandFlags struct {
+	Format   string
+	Fields   string
+	Filter   scan.Filter
+	Stale    string
+	ExitCode bool
+	Explain  string
+}
+
+// errReposMatched is returned by `scan --exit-code` when at least one repo
+// matched the filters, so main can exit non-zero without printing an error
+var errReposMatched = errors.New("repos matched")
+
+// errReposFailed is returned by `scan --exit-code` when a repo could not be
+// scanned, so whether it matches is unknown. main exits with status 2.
+var errReposFailed = errors.New("repos failed")
+
+// parseCommandFlags parses the flags of a subcommand and returns them
+// together with the remaining positional arguments (directories).
+// Commands without flags get their arguments back unchanged.
+func parseCommandFlags(cmd string, args []string) (commandFlags, []string, error) {
+	var flags commandFlags
+
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	switch cmd {
+	case "scan":
+		fs.StringVar(&flags.Format, "format", scan.FormatJSON, "Output format: "+strings.Join(scan.Formats, ", "))
+		fs.StringVar(&flags.Fields, "fields", "", "Comma separated fields to print (default depends on format)")
+		fs.BoolVar(&flags.Filter.Dirty, "dirty", false, "Only repos with uncommitted changes or unpushed commits")
+		fs.BoolVar(&flags.Filter.Clean, "clean", false, "Only repos with nothing to commit or push")
+		fs.BoolVar(&flags.Filter.Ahead, "ahead", false, "Only repos with commits not pushed (ahead of upstream or on no remote)")
+		fs.BoolVar(&flags.Filter.Behind, "behind", false, "Only repos behind their upstream")
+		fs.BoolVar(&flags.Filter.NoUpstream, "no-upstream", false, "Only repos on a branch without upstream, or with a gone one")
+		fs.StringVar(&flags.Filter.Branch, "branch", "", "Only repos whose branch matches this glob")
+		fs.StringVar(&flags.Filter.Name, "name", "", "Only repos whose name matches this glob")
+		fs.StringVar(&flags.Stale, "stale", "", "Only repos whose last commit is older than this (e.g. 30d, 2w)")
+		fs.BoolVar(&flags.ExitCode, "exit-code", false, "Exit with status 1 if any repo matches, 2 if any repo could not be scanned")
+		fs.StringVar(&flags.Explain, "explain", "", "Tell whether a directory is scanned, and which rule excludes it if not")
+	case "stashes", "unpushed":
+		fs.StringVar(&flags.Format, "format", "table", "Output format: table or json")
+	case "watch":
+		fs.StringVar(&flags.Format, "format", scan.FormatTable, "Output format: "+strings.Join(scan.Formats, ", "))
+		fs.StringVar(&flags.Fields, "fields", "", "Comma separated fields to print (default depends on format)")
+	default:
+		return flags, args, nil
+	}
+
+	// Parse stops at the first directory, so flags after it are parsed
+	// in another round. Everything after a "--" is a directory.
+	var dirs []string
+	rest := args
+	for {
+		if err := fs.Parse(rest); err != nil {
+			return flags, nil, err
+		}
+		parsed := len(rest) - len(fs.Args())
+		terminated := parsed > 0 && rest[parsed-1] == "--"
+		rest = fs.Args()
+		if len(rest) == 0 || terminated {
+			dirs = append(dirs, rest...)
+			break
+		}
+		dirs = append(dirs, rest[0])
+		rest = rest[1:]
+	}
+
+	if flags.Stale != "" {
+		age, err := scan.ParseAge(flags.Stale)
+		if err != nil {
+			return flags, nil, err
+		}
+		flags.Filter.Stale = age
+	}
+	if err := flags.Filter.Validate(); err != nil {
+		return flags, nil, err
+	}
+
+	return flags, dirs, nil
+}
+
 // run executes the requested command using the provided configuration path
 // and directories.
-func run(cmd string, dirs []string, configPath string) error {
+func run(cmd string, args []string, configPath string) error {
+	flags, dirs, err := parseCommandFlags(cmd, args)
+	if err == flag.ErrHelp {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
 	switch cmd {
 	case "init":
 		runInit()
@@ -139,24 +249,69 @@ func run(cmd string, dirs []string, configPath string) error {
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	gitstatus.Configure(gitstatus.ExecOptions{
+		Hardened:         cfg.Git.Hardened,
+		IgnoreUserConfig: cfg.Git.IgnoreUserConfig,
+		SafeDirectories:  cfg.Git.SafeDirectories,
+	})
 
 	if len(dirs) > 0 {
-		cfg.Roots = expandDirs(dirs)
+		// Directories on the command line replace the roots and repos of
+		// the config
+		cfg.Roots = config.RootsFor(expandDirs(dirs))
+		cfg.Repos = nil
 	} else if !config.ConfigExists(configPath) {
-		cfg.Roots = getSmartDefaults()
+		cfg.Roots = config.RootsFor(getSmartDefaults())
 	}
 
 	switch cmd {
 	case "scan":
-		repos, err := scan.ScanRoots(cfg.Roots, cfg.Ignore)
+		if flags.Explain != "" {
+			fmt.Println(scan.Explain(scan.OptionsFor(cfg), flags.Explain))
+			return nil
+		}
+		if !scan.IsFormat(flags.Format) {
+			return fmt.Errorf("unknown format %q (available: %s)", flags.Format, strings.Join(scan.Formats, ", "))
+		}
+		fields, err := scan.ParseFields(flags.Fields)
+		if err != nil {
+			return err
+		}
+		repos, err := scan.Collect(context.Background(), scan.OptionsFor(cfg))
 		if err != nil {
 			return fmt.Errorf("scan error: %w", err)
 		}
-		if err := scan.PrintJSON(os.Stdout, repos); err != nil {
+		var failed []model.Repo
+		for _, r := range repos {
+			if r.Status.ScanError != "" {
+				failed = append(failed, r)
+			}
+		}
+		repos = flags.Filter.Apply(repos)
+		if err := scan.Print(os.Stdout, repos, flags.Format, fields); err != nil {
 			return fmt.Errorf("print error: %w", err)
 		}
+		if flags.ExitCode && len(failed) > 0 {
+			// A repo that could not be checked must not pass the check
+			for _, r := range failed {
+				fmt.Fprintf(os.Stderr, "git-scope: could not scan %s: %s\n", r.Path, r.Status.ScanError)
+			}
+			return errReposFailed
+		}
+		if flags.ExitCode && len(repos) > 0 {
+			return errReposMatched
+		}
 		return nil
 
+	case "stashes":
+		return runStashes(cfg, flags.Format)
+
+	case "unpushed":
+		return runUnpushed(cfg, flags.Format)
+
+	case "watch":
+		return runWatch(cfg, flags.Format, flags.Fields)
+
 	case "tui", "":
 		if err := tui.Run(cfg); err != nil {
 			return fmt.Errorf("tui error: %w", err)
@@ -192,6 +347,29 @@ func expandDirs(dirs []string) []string {
 	return result
 }
 
+// uniqueRepos returns the repos sorted by path with linked worktrees
+// removed when their main repo shares the same git common directory,
+// so that per-repository data like stashes and branches is listed once
+func uniqueRepos(repos []model.Repo) []model.Repo {
+	sorted := make([]model.Repo, len(repos))
+	copy(sorted, repos)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Path < sorted[j].Path
+	})
+
+	seen := make(map[string]bool)
+	unique := make([]model.Repo, 0, len(sorted))
+	for _, r := range sorted {
+		layout, err := gitstatus.ResolveLayout(r.Path)
+		if err != nil || seen[layout.CommonDir] {
+			continue
+		}
+		seen[layout.CommonDir] = true
+		unique = append(unique, r)
+	}
+	return unique
+}
+
 // getSmartDefaults returns directories that likely contain git repos
 func getSmartDefaults() []string {
 	home, err := os.UserHomeDir()
@@ -365,7 +543,7 @@ func runScanAll() {
 		"Google Drive", "OneDrive", "Dropbox", "iCloud",
 	}
 
-	repos, err := scan.ScanRoots([]string{home}, ignorePatterns)
+	repos, err := scan.ScanRoots(context.Background(), []string{home}, ignorePatterns)
 	if err != nil {
 		log.Fatalf("scan error: %v", err)
 	}
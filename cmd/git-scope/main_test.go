@@ -0,0 +1,37 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCommandFlagsAfterDirs(t *testing.T) {
+	tests := []struct {
+		args     []string
+		format   string
+		ahead    bool
+		exitCode bool
+		dirs     []string
+	}{
+		{[]string{"--format", "table", "~/code"}, "table", false, false, []string{"~/code"}},
+		{[]string{"~/code", "--format", "table"}, "table", false, false, []string{"~/code"}},
+		{[]string{"~/code", "--ahead", "~/work", "--exit-code"}, "json", true, true, []string{"~/code", "~/work"}},
+		{[]string{"--ahead", "--", "~/code", "--exit-code"}, "json", true, false, []string{"~/code", "--exit-code"}},
+		{nil, "json", false, false, nil},
+	}
+	for _, tt := range tests {
+		flags, dirs, err := parseCommandFlags("scan", tt.args)
+		if err != nil {
+			t.Errorf("%v: %v", tt.args, err)
+			continue
+		}
+		if flags.Format != tt.format || flags.Filter.Ahead != tt.ahead || flags.ExitCode != tt.exitCode || !reflect.DeepEqual(dirs, tt.dirs) {
+			t.Errorf("%v: format %q, ahead %v, exit code %v, dirs %v; want %q, %v, %v, %v",
+				tt.args, flags.Format, flags.Filter.Ahead, flags.ExitCode, dirs, tt.format, tt.ahead, tt.exitCode, tt.dirs)
+		}
+	}
+
+	if _, _, err := parseCommandFlags("scan", []string{"~/code", "--no-such-flag"}); err == nil {
+		t.Error("an unknown flag after a directory was accepted")
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Bharath-code/git-scope/internal/config"
+	"github.com/Bharath-code/git-scope/internal/gitstatus"
+	"github.com/Bharath-code/git-scope/internal/model"
+	"github.com/Bharath-code/git-scope/internal/scan"
+	"github.com/Bharath-code/git-scope/internal/stats"
+)
+
+// stashEntry is a stash listed together with the repo it belongs to
+type stashEntry struct {
+	Repo    string    `json:"repo"`
+	Path    string    `json:"path"`
+	Ref     string    `json:"ref"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// runStashes prints every stash across all scanned repos
+func runStashes(cfg *config.Config, format string) error {
+	if format != "table" && format != "json" {
+		return fmt.Errorf("unknown format %q (expected table or json)", format)
+	}
+
+	repos, err := scan.Collect(context.Background(), scan.OptionsFor(cfg))
+	if err != nil {
+		return fmt.Errorf("scan error: %w", err)
+	}
+
+	entries := collectStashes(repos)
+
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+	return printStashTable(os.Stdout, entries)
+}
+
+// collectStashes reads the stash list of every repo, newest stash first
+func collectStashes(repos []model.Repo) []stashEntry {
+	entries := []stashEntry{}
+
+	for _, r := range uniqueRepos(repos) {
+		if r.Status.Stashes == 0 {
+			continue
+		}
+
+		stashes, err := gitstatus.Stashes(r.Path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: cannot read stashes in %s: %v\n", r.Path, err)
+			continue
+		}
+
+		for _, s := range stashes {
+			entries = append(entries, stashEntry{
+				Repo:    r.Name,
+				Path:    r.Path,
+				Ref:     s.Ref,
+				Message: s.Message,
+				Time:    s.Time,
+			})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+
+	return entries
+}
+
+// printStashTable prints stashes as an aligned text table
+func printStashTable(w io.Writer, entries []stashEntry) error {
+	if len(entries) == 0 {
+		_, err := fmt.Fprintln(w, "No stashes found.")
+		return err
+	}
+
+	now := time.Now()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "REPO\tSTASH\tAGE\tMESSAGE\tPATH")
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			e.Repo, e.Ref, stats.FormatTimeAgo(e.Time, now), strings.TrimSpace(e.Message), e.Path)
+	}
+	return tw.Flush()
+}
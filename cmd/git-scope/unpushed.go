@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Bharath-code/git-scope/internal/config"
+	"github.com/Bharath-code/git-scope/internal/model"
+	"github.com/Bharath-code/git-scope/internal/scan"
+)
+
+// unpushedRepo groups the unpushed branches of a single repo
+type unpushedRepo struct {
+	Repo     string                 `json:"repo"`
+	Path     string                 `json:"path"`
+	Branches []model.UnpushedBranch `json:"branches"`
+}
+
+// runUnpushed prints every local branch, across all scanned repos, that
+// has commits not reachable from any remote-tracking branch
+func runUnpushed(cfg *config.Config, format string) error {
+	if format != "table" && format != "json" {
+		return fmt.Errorf("unknown format %q (expected table or json)", format)
+	}
+
+	repos, err := scan.Collect(context.Background(), scan.OptionsFor(cfg))
+	if err != nil {
+		return fmt.Errorf("scan error: %w", err)
+	}
+
+	audit := []unpushedRepo{}
+	for _, r := range uniqueRepos(repos) {
+		if len(r.Status.UnpushedBranches) == 0 {
+			continue
+		}
+		audit = append(audit, unpushedRepo{
+			Repo:     r.Name,
+			Path:     r.Path,
+			Branches: r.Status.UnpushedBranches,
+		})
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(audit)
+	}
+	return printUnpushedTable(os.Stdout, audit)
+}
+
+// printUnpushedTable prints the unpushed branches as an aligned text table
+func printUnpushedTable(w io.Writer, audit []unpushedRepo) error {
+	if len(audit) == 0 {
+		_, err := fmt.Fprintln(w, "✓ Every local branch is on a remote.")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "REPO\tBRANCH\tCOMMITS\tPATH")
+	for _, r := range audit {
+		for _, b := range r.Branches {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", r.Repo, b.Name, b.Commits, r.Path)
+		}
+	}
+	return tw.Flush()
+}
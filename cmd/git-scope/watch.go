@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+
+	"github.com/Bharath-code/git-scope/internal/config"
+	"github.com/Bharath-code/git-scope/internal/model"
+	"github.com/Bharath-code/git-scope/internal/scan"
+	"github.com/Bharath-code/git-scope/internal/watch"
+)
+
+// runWatch prints every scanned repo, then keeps watching them and prints
+// each repo again whenever its status changes, until interrupted. Repos
+// created after the scan are not picked up.
+func runWatch(cfg *config.Config, format, fieldSpec string) error {
+	if !scan.IsFormat(format) {
+		return fmt.Errorf("unknown format %q (available: %s)", format, strings.Join(scan.Formats, ", "))
+	}
+	fields, err := scan.ParseFields(fieldSpec)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := scan.OptionsFor(cfg)
+	repos, err := scan.Collect(ctx, opts)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("scan error: %w", err)
+	}
+	if err := scan.Print(os.Stdout, repos, format, fields); err != nil {
+		return fmt.Errorf("print error: %w", err)
+	}
+
+	known := scan.ByPath(repos)
+	paths := make([]string, len(repos))
+	for i, r := range repos {
+		paths[i] = r.Path
+	}
+	w := watch.New(watch.Options{})
+	defer w.Close()
+	w.SetRepos(paths)
+	fmt.Fprintf(os.Stderr, "watching %d repos (%s), press Ctrl+C to stop\n", len(repos), w.Backend())
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case batch := <-w.Changes():
+			var changed []model.Repo
+			for _, path := range batch {
+				repo, err := scan.Refresh(ctx, opts, path)
+				if err != nil {
+					continue
+				}
+				repo.Aliases = known[path].Aliases
+				if !reflect.DeepEqual(known[path].Status, repo.Status) {
+					changed = append(changed, repo)
+				}
+				known[path] = repo
+			}
+			if len(changed) == 0 {
+				continue
+			}
+			if err := scan.Print(os.Stdout, changed, format, fields); err != nil {
+				return fmt.Errorf("print error: %w", err)
+			}
+		}
+	}
+}
@@ -14,12 +14,18 @@ type CacheData struct {
 	Repos     []model.Repo `json:"repos"`
 	Timestamp time.Time    `json:"timestamp"`
 	Roots     []string     `json:"roots"`
+	// Options is config.Config.ScanKey of the settings the repos were
+	// scanned with
+	Options string `json:"options,omitempty"`
+	// Fingerprints maps repo paths to model.Repo.Fingerprint, which is
+	// not part of the repo's JSON
+	Fingerprints map[string]string `json:"fingerprints,omitempty"`
 }
 
 // Store interface for caching repo data
 type Store interface {
 	Load() (*CacheData, error)
-	Save(repos []model.Repo, roots []string) error
+	Save(repos []model.Repo, roots []string, options string) error
 	IsValid(maxAge time.Duration) bool
 }
 
@@ -57,18 +63,63 @@ func (s *FileStore) Load() (*CacheData, error) {
 		return nil, err
 	}
 
+	for i := range cache.Repos {
+		cache.Repos[i].Fingerprint = cache.Fingerprints[cache.Repos[i].Path]
+	}
+
 	s.data = &cache
 	return &cache, nil
 }
 
 // Save writes repos to cache file
-func (s *FileStore) Save(repos []model.Repo, roots []string) error {
+func (s *FileStore) Save(repos []model.Repo, roots []string, options string) error {
 	cache := CacheData{
-		Repos:     repos,
-		Timestamp: time.Now(),
-		Roots:     roots,
+		Repos:        repos,
+		Timestamp:    time.Now(),
+		Roots:        roots,
+		Options:      options,
+		Fingerprints: make(map[string]string, len(repos)),
+	}
+	for _, r := range repos {
+		if r.Fingerprint != "" {
+			cache.Fingerprints[r.Path] = r.Fingerprint
+		}
+	}
+	return s.write(&cache)
+}
+
+// UpdateRepos replaces repos in the cache file, keeping the timestamp of
+// the scan the other repos came from. Repos that are not cached are
+// ignored.
+func (s *FileStore) UpdateRepos(repos ...model.Repo) error {
+	cache, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if cache.Fingerprints == nil {
+		cache.Fingerprints = make(map[string]string)
+	}
+
+	byPath := make(map[string]model.Repo, len(repos))
+	for _, r := range repos {
+		byPath[r.Path] = r
 	}
+	updated := false
+	for i := range cache.Repos {
+		if r, ok := byPath[cache.Repos[i].Path]; ok {
+			cache.Repos[i] = r
+			cache.Fingerprints[r.Path] = r.Fingerprint
+			updated = true
+		}
+	}
+	if !updated {
+		return nil
+	}
+	return s.write(cache)
+}
 
+// write stores cache data in the cache file
+func (s *FileStore) write(cache *CacheData) error {
 	// Ensure cache directory exists
 	dir := filepath.Dir(s.path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -91,9 +142,10 @@ func (s *FileStore) IsValid(maxAge time.Duration) bool {
 	return time.Since(s.data.Timestamp) < maxAge
 }
 
-// IsSameRoots checks if cached roots match current roots
-func (s *FileStore) IsSameRoots(roots []string) bool {
-	if s.data == nil || len(s.data.Roots) != len(roots) {
+// IsSameScan checks if the cache was saved by a scan of the current
+// roots with the current options
+func (s *FileStore) IsSameScan(roots []string, options string) bool {
+	if s.data == nil || s.data.Options != options || len(s.data.Roots) != len(roots) {
 		return false
 	}
 	for i, r := range roots {
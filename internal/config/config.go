@@ -1,20 +1,185 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Config holds the application configuration
 type Config struct {
-	Roots    []string `yaml:"roots"`
-	Ignore   []string `yaml:"ignore"`
-	Editor   string   `yaml:"editor"`
-	PageSize int      `yaml:"pageSize,omitempty"`
+	Roots []Root `yaml:"roots"`
+
+	// Ignore lists directories the walker skips: names or globs matched
+	// against the directory name ("build", "*.egg-info"), or path
+	// patterns with a slash ("~/code/archive/**"), relative to each root
+	// unless they start with "/" or "~/". "!pattern" re-includes.
+	Ignore []string `yaml:"ignore"`
+
+	// Include re-includes directories that the preset or Ignore would
+	// skip, with the same pattern syntax
+	Include []string `yaml:"include,omitempty"`
+
+	// IgnorePreset names the built-in list of tool and cache directories
+	// skipped on top of Ignore, see IgnorePresets (default: smart)
+	IgnorePreset string `yaml:"ignorePreset,omitempty"`
+
+	// Repos are always scanned, whatever the ignore rules and whether or
+	// not they are below a root
+	Repos []string `yaml:"repos,omitempty"`
+
+	Editor   string `yaml:"editor"`
+	PageSize int    `yaml:"pageSize,omitempty"`
+
+	// Concurrency limits parallel git status calls (0 = based on CPU count)
+	Concurrency int `yaml:"concurrency,omitempty"`
+
+	// RepoTimeout bounds the git calls for a single repo, e.g. "30s"
+	// (0 = default timeout)
+	RepoTimeout time.Duration `yaml:"repoTimeout,omitempty"`
+
+	// DescendIntoRepos keeps searching the working trees of found repos
+	// for nested repos, submodules and vendored checkouts (default: true)
+	DescendIntoRepos bool `yaml:"descendIntoRepos"`
+
+	// Watch starts the TUI with auto-refresh on, updating repos as they
+	// change on disk
+	Watch bool `yaml:"watch,omitempty"`
+
+	Git GitConfig `yaml:"git"`
+}
+
+// IgnorePresets are the named lists of directories an IgnorePreset can
+// select. "smart" holds system and tool directories that rarely contain
+// your own repos but are slow to walk.
+var IgnorePresets = map[string][]string{
+	"smart": {
+		// macOS/Linux system directories
+		"Library", ".Trash", ".cache", ".local",
+		// Package managers & runtimes
+		".npm", ".yarn", ".pnpm", ".bun", ".cargo", ".rustup", ".go",
+		".venv", ".pyenv", ".rbenv", ".nvm", ".sdkman",
+		// IDE extensions (contain third-party repos, not your code)
+		".vscode", ".vscode-server", ".cursor", ".zed", ".idea", ".atom",
+		// Shell & tools configs
+		".oh-my-zsh", ".tmux", ".vim", ".emacs.d", ".gemini",
+		// Docker/Cloud
+		".docker", ".kube", ".ssh", ".gnupg",
+		// Cloud sync (slow and likely duplicates)
+		"Google Drive", "OneDrive", "Dropbox", "iCloud",
+	},
+	"none": nil,
+}
+
+// DefaultIgnorePreset is used when the config doesn't name one
+const DefaultIgnorePreset = "smart"
+
+// PresetPatterns returns the patterns of the configured ignore preset
+func (c *Config) PresetPatterns() []string {
+	if c.IgnorePreset == "" {
+		return IgnorePresets[DefaultIgnorePreset]
+	}
+	return IgnorePresets[c.IgnorePreset]
+}
+
+// Root is a directory scanned for repos. In YAML it is either a plain
+// path or a mapping with path, maxDepth and its own ignore rules.
+type Root struct {
+	Path string `yaml:"path"`
+
+	// MaxDepth is how many directory levels below Path are searched;
+	// repos directly in Path are at depth 1 (0 = unlimited)
+	MaxDepth int `yaml:"maxDepth,omitempty"`
+
+	// Ignore and Include apply below this root only, after the global
+	// lists. Path patterns are relative to Path.
+	Ignore  []string `yaml:"ignore,omitempty"`
+	Include []string `yaml:"include,omitempty"`
+}
+
+// UnmarshalYAML accepts a plain path as well as the mapping form
+func (r *Root) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		r.Path = node.Value
+		return nil
+	}
+	type plain Root
+	return node.Decode((*plain)(r))
+}
+
+// MarshalYAML writes roots without settings of their own as plain paths
+func (r Root) MarshalYAML() (interface{}, error) {
+	if r.MaxDepth == 0 && len(r.Ignore) == 0 && len(r.Include) == 0 {
+		return r.Path, nil
+	}
+	type plain Root
+	return plain(r), nil
+}
+
+// RootsFor returns roots without settings of their own for the given paths
+func RootsFor(paths []string) []Root {
+	roots := make([]Root, len(paths))
+	for i, p := range paths {
+		roots[i] = Root{Path: p}
+	}
+	return roots
+}
+
+// RootPaths returns the paths of the configured roots
+func (c *Config) RootPaths() []string {
+	paths := make([]string, len(c.Roots))
+	for i, r := range c.Roots {
+		paths[i] = r.Path
+	}
+	return paths
+}
+
+// ScanKey hashes the settings that decide which repos a scan finds and
+// how their status is read, so cached results from other settings are
+// not mistaken for current ones
+func (c *Config) ScanKey() string {
+	data, err := json.Marshal(struct {
+		Roots            []Root
+		Ignore           []string
+		Include          []string
+		Preset           []string
+		Repos            []string
+		DescendIntoRepos bool
+		Git              GitConfig
+	}{c.Roots, c.Ignore, c.Include, c.PresetPatterns(), c.Repos, c.DescendIntoRepos, c.Git})
+	if err != nil {
+		return ""
+	}
+	h := fnv.New64a()
+	h.Write(data)
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// GitConfig controls how git is run on scanned repos
+type GitConfig struct {
+	// Backend selects how repo status is read: "exec" runs git, "native"
+	// reads the repository files directly (default: exec)
+	Backend string `yaml:"backend,omitempty"`
+
+	// Hardened keeps git from starting programs named in a repo's config
+	// (fsmonitor hooks, gpg.program, pager, hooks) and from taking
+	// optional index locks, and drops inherited GIT_* variables
+	// (default: true)
+	Hardened bool `yaml:"hardened"`
+
+	// IgnoreUserConfig skips the system and global git config
+	IgnoreUserConfig bool `yaml:"ignoreUserConfig,omitempty"`
+
+	// SafeDirectories lists repos owned by another user that git-scope may
+	// read anyway, without adding them to the global safe.directory
+	SafeDirectories []string `yaml:"safeDirectories,omitempty"`
 }
 
 // defaultConfig returns sensible defaults
@@ -28,7 +193,7 @@ func defaultConfig() *Config {
 	}
 
 	return &Config{
-		Roots: []string{cwd},
+		Roots: []Root{{Path: cwd}},
 		Ignore: []string{
 			"node_modules",
 			".next",
@@ -38,8 +203,10 @@ func defaultConfig() *Config {
 			".venv",
 			"vendor",
 		},
-		Editor:   "code",
-		PageSize: 15,
+		Editor:           "code",
+		PageSize:         15,
+		DescendIntoRepos: true,
+		Git:              GitConfig{Hardened: true},
 	}
 }
 
@@ -62,7 +229,32 @@ func Load(path string) (*Config, error) {
 
 	// Expand ~ in paths
 	for i, root := range cfg.Roots {
-		cfg.Roots[i] = expandPath(root)
+		if root.MaxDepth < 0 {
+			return nil, fmt.Errorf("parse config: negative maxDepth for root %s", root.Path)
+		}
+		cfg.Roots[i].Path = expandPath(root.Path)
+	}
+
+	for i, repo := range cfg.Repos {
+		cfg.Repos[i] = expandPath(repo)
+	}
+
+	if _, ok := IgnorePresets[cfg.IgnorePreset]; !ok && cfg.IgnorePreset != "" {
+		return nil, fmt.Errorf("parse config: unknown ignorePreset %q (available: smart, none)", cfg.IgnorePreset)
+	}
+
+	// Expand ~ in trusted repo paths
+	for i, dir := range cfg.Git.SafeDirectories {
+		// "*" trusts every repo and must reach git unchanged
+		if dir != "*" {
+			cfg.Git.SafeDirectories[i] = expandPath(dir)
+		}
+	}
+
+	switch cfg.Git.Backend {
+	case "", "exec", "native":
+	default:
+		return nil, fmt.Errorf("parse config: unknown git.backend %q (available: exec, native)", cfg.Git.Backend)
 	}
 
 	// Ensure pageSize has a sensible value
@@ -120,7 +312,7 @@ func CreateConfig(path string, roots []string, editor string) error {
 	}
 
 	cfg := &Config{
-		Roots: roots,
+		Roots: RootsFor(roots),
 		Ignore: []string{
 			"node_modules",
 			".next",
@@ -130,7 +322,9 @@ func CreateConfig(path string, roots []string, editor string) error {
 			".venv",
 			"vendor",
 		},
-		Editor: editor,
+		Editor:           editor,
+		DescendIntoRepos: true,
+		Git:              GitConfig{Hardened: true},
 	}
 
 	data, err := yaml.Marshal(cfg)
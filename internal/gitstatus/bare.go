@@ -0,0 +1,101 @@
+package gitstatus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Bharath-code/git-scope/internal/model"
+)
+
+// IsBare reports whether dir is a bare repository: a directory with a
+// HEAD holding a ref or commit id, objects and refs, and no .git entry of
+// its own
+func IsBare(dir string) bool {
+	if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+		return false
+	}
+	head, err := os.ReadFile(filepath.Join(dir, "HEAD"))
+	if err != nil {
+		return false
+	}
+	head = bytes.TrimSpace(head)
+	if _, ok := parseObjectID(string(head)); !ok && !bytes.HasPrefix(head, []byte("ref: ")) {
+		return false
+	}
+	for _, sub := range []string{"objects", "refs"} {
+		if info, err := os.Stat(filepath.Join(dir, sub)); err != nil || !info.IsDir() {
+			return false
+		}
+	}
+	return true
+}
+
+// BareStatus reports on a bare repository: the branch HEAD points to, the
+// branches with their last commit, the size of the object database and
+// when it was last fetched into. The worktree counts stay zero.
+func BareStatus(ctx context.Context, repoPath string) (model.RepoStatus, error) {
+	var status model.RepoStatus
+	info := &model.BareInfo{Branches: []model.BranchTip{}}
+	status.Bare = info
+
+	if head, err := os.ReadFile(filepath.Join(repoPath, "HEAD")); err == nil {
+		status.Branch = strings.TrimPrefix(strings.TrimSpace(string(head)), "ref: refs/heads/")
+	}
+
+	out, err := Git(ctx, repoPath, "for-each-ref", "--format=%(refname:short)%00%(committerdate:unix)", "refs/heads")
+	if err != nil {
+		return status, fmt.Errorf("git for-each-ref: %w", err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		name, ts, ok := strings.Cut(line, "\x00")
+		if !ok {
+			continue
+		}
+		tip := model.BranchTip{Name: name}
+		if sec, err := strconv.ParseInt(ts, 10, 64); err == nil {
+			tip.LastCommit = time.Unix(sec, 0)
+		}
+		info.Branches = append(info.Branches, tip)
+	}
+	sort.SliceStable(info.Branches, func(i, j int) bool {
+		return info.Branches[i].LastCommit.After(info.Branches[j].LastCommit)
+	})
+	if len(info.Branches) > 0 {
+		status.LastCommit = info.Branches[0].LastCommit
+	}
+
+	cfg := make(gitConfig)
+	if data, err := os.ReadFile(filepath.Join(repoPath, "config")); err == nil {
+		parseGitConfig(string(data), cfg)
+	}
+	for key := range cfg {
+		if strings.HasPrefix(key, "remote.") && strings.HasSuffix(key, ".mirror") && cfg.bool(key, false) {
+			info.Mirror = true
+		}
+	}
+
+	// FETCH_HEAD is rewritten by every fetch, including `git remote update`
+	if fi, err := os.Stat(filepath.Join(repoPath, "FETCH_HEAD")); err == nil {
+		info.LastFetch = fi.ModTime()
+	}
+
+	_ = filepath.WalkDir(filepath.Join(repoPath, "objects"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if fi, err := d.Info(); err == nil {
+			info.SizeBytes += fi.Size()
+		}
+		return nil
+	})
+
+	return status, nil
+}
@@ -0,0 +1,71 @@
+package gitstatus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Bharath-code/git-scope/internal/model"
+)
+
+// unpushedBranches audits every local branch and returns the ones with
+// commits that are not reachable from any remote-tracking ref
+func unpushedBranches(ctx context.Context, repoPath string) ([]model.UnpushedBranch, error) {
+	// One walk lists every local-only commit with its parents, so fully
+	// pushed repos cost a single git call
+	out, err := Git(ctx, repoPath, "rev-list", "--parents", "--branches", "--not", "--remotes")
+	if err != nil {
+		return nil, fmt.Errorf("git rev-list: %w", err)
+	}
+	parents := make(map[string][]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			parents[fields[0]] = fields[1:]
+		}
+	}
+	if len(parents) == 0 {
+		return nil, nil
+	}
+
+	out, err = Git(ctx, repoPath, "for-each-ref", "--format=%(objectname) %(refname)", "refs/heads")
+	if err != nil {
+		return nil, fmt.Errorf("git for-each-ref: %w", err)
+	}
+
+	var branches []model.UnpushedBranch
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		id, ref, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		if n := countLocalOnly(id, parents); n > 0 {
+			branches = append(branches, model.UnpushedBranch{
+				Name:    strings.TrimPrefix(ref, "refs/heads/"),
+				Commits: n,
+			})
+		}
+	}
+
+	return branches, nil
+}
+
+// countLocalOnly counts the local-only commits reachable from tip. A
+// parent missing from parents is on a remote, and so is its history.
+func countLocalOnly(tip string, parents map[string][]string) int {
+	if _, ok := parents[tip]; !ok {
+		return 0
+	}
+	seen := map[string]bool{tip: true}
+	stack := []string{tip}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, p := range parents[id] {
+			if _, local := parents[p]; local && !seen[p] {
+				seen[p] = true
+				stack = append(stack, p)
+			}
+		}
+	}
+	return len(seen)
+}
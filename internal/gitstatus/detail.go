@@ -0,0 +1,230 @@
+package gitstatus
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Bharath-code/git-scope/internal/model"
+)
+
+// FileChange is a single path reported by `git status`
+type FileChange struct {
+	Path       string
+	OrigPath   string // Source path of a rename or copy
+	Staged     bool
+	Unstaged   bool
+	Untracked  bool
+	Conflicted bool
+}
+
+// Commit is a summary of a single commit
+type Commit struct {
+	Hash    string
+	Author  string
+	Subject string
+	Time    time.Time
+}
+
+// Remote is a configured remote with its fetch URL
+type Remote struct {
+	Name string
+	URL  string
+}
+
+// Detail holds everything the detail pane shows for a single repository
+type Detail struct {
+	Files   []FileChange
+	Commits []Commit
+	Remotes []Remote
+	Stashes []model.Stash
+}
+
+// GetDetail collects the changed paths, the last `commits` commits on the
+// current branch, the remotes and the stash entries of a repository. For a
+// bare repository there are no changed paths.
+func GetDetail(ctx context.Context, repoPath string, commits int) (*Detail, error) {
+	detail := &Detail{}
+
+	// A bare repository has no working tree, so nothing has changed
+	if !IsBare(repoPath) {
+		files, err := changedFiles(ctx, repoPath)
+		if err != nil {
+			return nil, err
+		}
+		detail.Files = files
+	}
+
+	// The remaining sections are best effort: an empty repo has no
+	// commits and a local-only repo has no remotes
+	if c, err := recentCommits(ctx, repoPath, commits); err == nil {
+		detail.Commits = c
+	}
+	if r, err := remotes(ctx, repoPath); err == nil {
+		detail.Remotes = r
+	}
+	if s, err := Stashes(repoPath); err == nil {
+		detail.Stashes = s
+	}
+
+	return detail, nil
+}
+
+// changedFiles lists the staged, modified, untracked and conflicted paths
+// of a repository. It uses NUL-terminated porcelain v2 output so paths
+// are reported verbatim instead of quoted.
+func changedFiles(ctx context.Context, repoPath string) ([]FileChange, error) {
+	out, err := Git(ctx, repoPath, "status", "--porcelain=v2", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("git status: %w", err)
+	}
+
+	var files []FileChange
+	records := strings.Split(string(out), "\x00")
+	for i := 0; i < len(records); i++ {
+		rec := records[i]
+		switch {
+		case strings.HasPrefix(rec, "1 "):
+			// 1 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <path>
+			parts := strings.SplitN(rec, " ", 9)
+			if len(parts) < 9 {
+				continue
+			}
+			staged, unstaged := parseXY(rec)
+			files = append(files, FileChange{Path: parts[8], Staged: staged, Unstaged: unstaged})
+
+		case strings.HasPrefix(rec, "2 "):
+			// 2 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <X><score> <path>, then <origPath>
+			parts := strings.SplitN(rec, " ", 10)
+			if len(parts) < 10 {
+				continue
+			}
+			staged, unstaged := parseXY(rec)
+			change := FileChange{Path: parts[9], Staged: staged, Unstaged: unstaged}
+			if i+1 < len(records) {
+				i++
+				change.OrigPath = records[i]
+			}
+			files = append(files, change)
+
+		case strings.HasPrefix(rec, "u "):
+			// u <XY> <sub> <m1> <m2> <m3> <mW> <h1> <h2> <h3> <path>
+			parts := strings.SplitN(rec, " ", 11)
+			if len(parts) < 11 {
+				continue
+			}
+			files = append(files, FileChange{Path: parts[10], Conflicted: true})
+
+		case strings.HasPrefix(rec, "? "):
+			files = append(files, FileChange{Path: strings.TrimPrefix(rec, "? "), Untracked: true})
+		}
+	}
+
+	return files, nil
+}
+
+// recentCommits returns the last n commits reachable from HEAD
+func recentCommits(ctx context.Context, repoPath string, n int) ([]Commit, error) {
+	out, err := Git(ctx, repoPath, "log", "-n", strconv.Itoa(n), "--format=%h%x00%an%x00%ct%x00%s")
+	if err != nil {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+
+	var commits []Commit
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		parts := strings.SplitN(line, "\x00", 4)
+		if len(parts) < 4 {
+			continue
+		}
+
+		commit := Commit{Hash: parts[0], Author: parts[1], Subject: parts[3]}
+		if sec, err := strconv.ParseInt(parts[2], 10, 64); err == nil {
+			commit.Time = time.Unix(sec, 0)
+		}
+		commits = append(commits, commit)
+	}
+
+	return commits, nil
+}
+
+// remotes lists the configured remotes with their fetch URLs
+func remotes(ctx context.Context, repoPath string) ([]Remote, error) {
+	out, err := Git(ctx, repoPath, "remote", "-v")
+	if err != nil {
+		return nil, fmt.Errorf("git remote: %w", err)
+	}
+
+	var list []Remote
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		// <name>\t<url> (fetch|push)
+		if !strings.HasSuffix(line, " (fetch)") {
+			continue
+		}
+		name, url, ok := strings.Cut(strings.TrimSuffix(line, " (fetch)"), "\t")
+		if !ok {
+			continue
+		}
+		list = append(list, Remote{Name: name, URL: url})
+	}
+
+	return list, nil
+}
+
+// diffMaxBytes caps the diff output kept for a single file preview
+const diffMaxBytes = 512 * 1024
+
+// FileDiff returns the diff of a single changed path: staged changes
+// (`git diff --cached`) followed by unstaged changes (`git diff`).
+// Untracked files are diffed against /dev/null so their full content
+// shows as added. External diff drivers and textconv filters are
+// disabled so previewing never runs repository-configured commands.
+func FileDiff(ctx context.Context, repoPath string, f FileChange) (string, error) {
+	var b strings.Builder
+
+	if f.Untracked && strings.HasSuffix(f.Path, "/") {
+		return fmt.Sprintf("Untracked directory %s has no diff to preview.\n", f.Path), nil
+	}
+
+	if f.Untracked {
+		// --no-index exits with status 1 when the files differ, which is
+		// always the case here; only trust the error if there is no output
+		out, err := Git(ctx, repoPath, "diff", "--no-color", "--no-ext-diff", "--no-textconv", "--no-index", "--", "/dev/null", f.Path)
+		if err != nil && len(out) == 0 {
+			return "", fmt.Errorf("git diff: %w", err)
+		}
+		b.Write(out)
+		return capDiff(b.String()), nil
+	}
+
+	if f.Staged {
+		out, err := Git(ctx, repoPath, "diff", "--cached", "--no-color", "--no-ext-diff", "--no-textconv", "--", f.Path)
+		if err != nil {
+			return "", fmt.Errorf("git diff --cached: %w", err)
+		}
+		b.Write(out)
+	}
+
+	if f.Unstaged || f.Conflicted {
+		out, err := Git(ctx, repoPath, "diff", "--no-color", "--no-ext-diff", "--no-textconv", "--", f.Path)
+		if err != nil {
+			return "", fmt.Errorf("git diff: %w", err)
+		}
+		b.Write(out)
+	}
+
+	return capDiff(b.String()), nil
+}
+
+// capDiff truncates very large diffs to keep the preview responsive
+func capDiff(diff string) string {
+	if len(diff) <= diffMaxBytes {
+		return diff
+	}
+	cut := strings.LastIndex(diff[:diffMaxBytes], "\n")
+	if cut < 0 {
+		cut = diffMaxBytes
+	}
+	return diff[:cut] + "\n... diff truncated ...\n"
+}
@@ -0,0 +1,183 @@
+package gitstatus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ExecOptions controls how git-scope runs git
+type ExecOptions struct {
+	// Hardened runs git without the side effects a scanned repo can
+	// trigger through its own config: programs the repo's config names
+	// (fsmonitor hooks, signature checkers, pagers, hooks) are never
+	// started, optional locks (index refreshes) are skipped and inherited
+	// GIT_* variables that point git elsewhere are dropped.
+	//
+	// Clean/smudge filters declared in a repo's .gitattributes can still
+	// run when git has to compare file contents.
+	Hardened bool
+
+	// IgnoreUserConfig makes git read neither the system nor the global
+	// config, only the repo's own
+	IgnoreUserConfig bool
+
+	// SafeDirectories are repos git may open even though they are owned
+	// by another user. They are passed on the command line, so they only
+	// apply to git-scope's own invocations.
+	SafeDirectories []string
+}
+
+// ErrDubiousOwnership is returned by Git when git refuses to open a repo
+// because it is owned by another user and not listed in safe.directory
+var ErrDubiousOwnership = errors.New("detected dubious ownership")
+
+// execOptions are the settings used by Git. Hardened is the default.
+var execOptions = ExecOptions{Hardened: true}
+
+// Configure sets how git is run. It must be called before any scan starts.
+func Configure(opts ExecOptions) {
+	execOptions = opts
+}
+
+// hardenedConfig is passed as -c overrides, which take precedence over
+// anything set in the repo's config. Each one turns off a program the
+// repo's config could otherwise make git start. External diff drivers
+// and textconv are turned off per call by FileDiff instead, with
+// --no-ext-diff and --no-textconv.
+var hardenedConfig = []string{
+	"core.fsmonitor=false",         // fsmonitor hook on status
+	"log.showSignature=false",      // gpg.program on log
+	"core.pager=cat",               // pager, should git ever think it writes to a terminal
+	"core.hooksPath=" + os.DevNull, // hooks, should a command ever trigger one
+}
+
+// keptGitEnv are the GIT_* variables a hardened run keeps. They select
+// the user's own config files and git installation, not a repository.
+var keptGitEnv = map[string]bool{
+	"GIT_CONFIG_GLOBAL":   true,
+	"GIT_CONFIG_SYSTEM":   true,
+	"GIT_CONFIG_NOSYSTEM": true,
+	"GIT_EXEC_PATH":       true,
+}
+
+// Git executes a git command with the given arguments in the specified
+// directory and returns its stdout output. Every git process git-scope
+// starts goes through here so the ExecOptions apply everywhere. The
+// process is killed when ctx ends, in which case the context's error is
+// returned.
+func Git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", gitArgs(execOptions, args)...)
+	cmd.Dir = dir
+	cmd.Env = gitEnv(execOptions, os.Environ())
+	// Don't wait forever on pipes held open by hooks git spawned
+	cmd.WaitDelay = time.Second
+
+	out, err := cmd.Output()
+	if err != nil && ctx.Err() != nil {
+		return out, ctx.Err()
+	}
+	// Output collects stderr into the ExitError, but its message is just
+	// the exit status; attach what git actually said
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if strings.Contains(string(exitErr.Stderr), "detected dubious ownership") {
+			return out, fmt.Errorf("%w in repository at %s", ErrDubiousOwnership, dir)
+		}
+		if msg := stderrMessage(exitErr.Stderr); msg != "" {
+			return out, fmt.Errorf("%w: %s", err, msg)
+		}
+	}
+	return out, err
+}
+
+// stderrMessage picks the line of git's stderr that explains a failure:
+// the first fatal or error line, otherwise the last non-empty one
+func stderrMessage(stderr []byte) string {
+	var last string
+	for _, line := range strings.Split(string(stderr), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "fatal:") || strings.HasPrefix(line, "error:") {
+			return line
+		}
+		if line != "" {
+			last = line
+		}
+	}
+	return last
+}
+
+// SafeDirectoryFix returns the command that marks a repo as safe for
+// every git invocation of the current user
+func SafeDirectoryFix(repoPath string) string {
+	return "git config --global --add safe.directory " + shellQuote(repoPath)
+}
+
+// shellQuote quotes s for a POSIX shell if it contains anything but
+// plain path characters
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789/._-+:@~") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// gitArgs prepends the config overrides of the options to a git command
+func gitArgs(opts ExecOptions, args []string) []string {
+	var overrides []string
+	if opts.Hardened {
+		overrides = append(overrides, hardenedConfig...)
+	}
+	// safe.directory is only honoured in protected config, which
+	// includes the command line but never the repo's own config
+	for _, dir := range opts.SafeDirectories {
+		overrides = append(overrides, "safe.directory="+dir)
+	}
+	if len(overrides) == 0 {
+		return args
+	}
+
+	full := make([]string, 0, 2*len(overrides)+len(args))
+	for _, kv := range overrides {
+		full = append(full, "-c", kv)
+	}
+	return append(full, args...)
+}
+
+// gitEnv builds the environment of a git process from the inherited one.
+// It returns nil, meaning inherit unchanged, if no option applies.
+func gitEnv(opts ExecOptions, environ []string) []string {
+	if !opts.Hardened && !opts.IgnoreUserConfig {
+		return nil
+	}
+
+	env := make([]string, 0, len(environ)+4)
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		if opts.Hardened && strings.HasPrefix(name, "GIT_") && !keptGitEnv[name] {
+			continue
+		}
+		if opts.IgnoreUserConfig && (name == "GIT_CONFIG_GLOBAL" || name == "GIT_CONFIG_SYSTEM" || name == "GIT_CONFIG_NOSYSTEM") {
+			continue
+		}
+		env = append(env, kv)
+	}
+
+	if opts.Hardened {
+		env = append(env,
+			"GIT_OPTIONAL_LOCKS=0",  // Never take index.lock just to refresh stat info
+			"GIT_TERMINAL_PROMPT=0", // Never block on a credential prompt
+		)
+	}
+	if opts.IgnoreUserConfig {
+		env = append(env,
+			"GIT_CONFIG_NOSYSTEM=1",
+			"GIT_CONFIG_GLOBAL="+os.DevNull,
+		)
+	}
+	return env
+}
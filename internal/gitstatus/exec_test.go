@@ -0,0 +1,136 @@
+package gitstatus
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestHardenedSkipsFsmonitor checks that a core.fsmonitor hook configured
+// by a scanned repo is never executed in hardened mode
+func TestHardenedSkipsFsmonitor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fsmonitor hook fixture is a shell script")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	marker := filepath.Join(t.TempDir(), "fsmonitor-ran")
+	hook := filepath.Join(t.TempDir(), "fsmonitor.sh")
+	script := "#!/bin/sh\ntouch '" + marker + "'\n"
+	if err := os.WriteFile(hook, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "core.fsmonitor", hook},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(repo, "file.txt"), []byte("x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer Configure(execOptions)
+	ctx := context.Background()
+
+	// Make sure the fixture works: an unhardened status runs the hook
+	Configure(ExecOptions{})
+	if _, err := Status(ctx, repo); err != nil {
+		t.Fatalf("unhardened status: %v", err)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Skip("this git does not run fsmonitor hooks")
+	}
+	if err := os.Remove(marker); err != nil {
+		t.Fatal(err)
+	}
+
+	Configure(ExecOptions{Hardened: true})
+	if _, err := Status(ctx, repo); err != nil {
+		t.Fatalf("hardened status: %v", err)
+	}
+	if _, err := GetDetail(ctx, repo, 5); err != nil {
+		t.Fatalf("hardened detail: %v", err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("fsmonitor hook ran in hardened mode")
+	}
+	if _, err := os.Stat(filepath.Join(repo, ".git", "index.lock")); err == nil {
+		t.Fatal("index.lock left behind in hardened mode")
+	}
+}
+
+// TestHardenedSkipsSignatureCheck checks that a gpg.program configured by
+// a scanned repo is never executed in hardened mode, even when the repo
+// also turns on log.showSignature and HEAD carries a signature
+func TestHardenedSkipsSignatureCheck(t *testing.T) {
+	f := newFixture(t)
+	marker := filepath.Join(t.TempDir(), "gpg-ran")
+	f.write("gpg.sh", "#!/bin/sh\ntouch '"+marker+"'\n")
+	if err := os.Chmod(filepath.Join(f.dir, "gpg.sh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	f.git("", "init", "-q", "repo")
+	f.git("repo", "config", "log.showSignature", "true")
+	f.git("repo", "config", "gpg.program", filepath.Join(f.dir, "gpg.sh"))
+
+	// A commit with a gpgsig header, written by hand since signing for
+	// real would need a key
+	commit := "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n" +
+		"author Test <test@example.com> 1700000000 +0000\n" +
+		"committer Test <test@example.com> 1700000000 +0000\n" +
+		"gpgsig -----BEGIN PGP SIGNATURE-----\n" +
+		" \n" +
+		" c2lnbmF0dXJl\n" +
+		" -----END PGP SIGNATURE-----\n" +
+		"\n" +
+		"signed\n"
+	repo := filepath.Join(f.dir, "repo")
+	cmd := exec.Command("git", "hash-object", "-t", "commit", "-w", "--stdin")
+	cmd.Dir = repo
+	cmd.Stdin = strings.NewReader(commit)
+	id, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git hash-object: %v", err)
+	}
+	f.git("repo", "update-ref", "HEAD", strings.TrimSpace(string(id)))
+
+	defer Configure(execOptions)
+	ctx := context.Background()
+
+	// Make sure the fixture works: an unhardened status runs gpg
+	Configure(ExecOptions{})
+	if _, err := Status(ctx, repo); err != nil {
+		t.Fatalf("unhardened status: %v", err)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Skip("this git does not check signatures on log")
+	}
+	if err := os.Remove(marker); err != nil {
+		t.Fatal(err)
+	}
+
+	Configure(ExecOptions{Hardened: true})
+	if _, err := Status(ctx, repo); err != nil {
+		t.Fatalf("hardened status: %v", err)
+	}
+	if _, err := GetDetail(ctx, repo, 5); err != nil {
+		t.Fatalf("hardened detail: %v", err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("gpg.program ran in hardened mode")
+	}
+}
@@ -0,0 +1,113 @@
+package gitstatus
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Fingerprint summarizes the stat data of the files git rewrites when
+// the status of a working tree changes: the index, HEAD, the loose refs
+// and packed-refs, the config (upstreams), FETCH_HEAD (fetch age of bare
+// repos), the git directory itself
+// (merge and rebase state) and the worktree root (new top-level files).
+// It also covers the worktree side of the index: every tracked file
+// (unstaged edits) and every directory holding one (files created or
+// deleted next to tracked files).
+// An unchanged fingerprint means the status can be reused.
+//
+// New files inside untracked directories and changes inside submodules
+// leave it unchanged.
+func Fingerprint(repoPath string, layout Layout) string {
+	return fingerprint(repoPath, layout, false)
+}
+
+// ContentFingerprint is Fingerprint with the index summarized by IndexKey
+// and the git directory by the names in it. It stays the same when git
+// status rewrites the index just to refresh cached stat data, as it does
+// unless run with GIT_OPTIONAL_LOCKS=0.
+func ContentFingerprint(repoPath string, layout Layout) string {
+	return fingerprint(repoPath, layout, true)
+}
+
+// fingerprint implements Fingerprint and ContentFingerprint
+func fingerprint(repoPath string, layout Layout, content bool) string {
+	h := fnv.New64a()
+	add := func(path string) {
+		info, err := os.Stat(path)
+		if err != nil {
+			fmt.Fprintf(h, "%s -\n", path)
+			return
+		}
+		fmt.Fprintf(h, "%s %d %d\n", path, info.Size(), info.ModTime().UnixNano())
+	}
+
+	idx, idxErr := readIndex(layout.GitDir)
+
+	add(repoPath)
+	if content {
+		var key string
+		if idxErr == nil {
+			key = idx.key()
+		} else {
+			key = IndexKey(layout.GitDir)
+		}
+		fmt.Fprintf(h, "index %s\n", key)
+		// Names only: rewriting the index through index.lock changes
+		// the directory's modification time
+		if entries, err := os.ReadDir(layout.GitDir); err == nil {
+			for _, e := range entries {
+				if e.Name() != "index" && !strings.HasSuffix(e.Name(), ".lock") {
+					fmt.Fprintf(h, "%s\n", e.Name())
+				}
+			}
+		}
+	} else {
+		add(layout.GitDir)
+		add(filepath.Join(layout.GitDir, "index"))
+	}
+	add(filepath.Join(layout.GitDir, "HEAD"))
+	add(filepath.Join(layout.CommonDir, "packed-refs"))
+	add(filepath.Join(layout.CommonDir, "config"))
+	add(filepath.Join(layout.CommonDir, "FETCH_HEAD"))
+
+	refDirs := []string{filepath.Join(layout.CommonDir, "refs")}
+	if layout.GitDir != layout.CommonDir {
+		// Per-worktree refs such as refs/bisect
+		refDirs = append(refDirs, filepath.Join(layout.GitDir, "refs"))
+	}
+	for _, dir := range refDirs {
+		_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err == nil {
+				add(path)
+			}
+			return nil
+		})
+	}
+
+	if idxErr == nil {
+		for _, dir := range idx.trackedDirs() {
+			if dir != "" {
+				add(filepath.Join(repoPath, filepath.FromSlash(dir)))
+			}
+		}
+		// Lstat, as the index records symlinks themselves. Editors that
+		// save in place change neither the index nor any directory.
+		for _, e := range idx.entries {
+			if e.skipWorktree || e.mode&modeTypeMask == modeGitlink {
+				continue
+			}
+			path := filepath.Join(repoPath, filepath.FromSlash(e.path))
+			if info, err := os.Lstat(path); err == nil {
+				fmt.Fprintf(h, "%s %d %d %o\n", e.path, info.Size(), info.ModTime().UnixNano(), info.Mode())
+			} else {
+				fmt.Fprintf(h, "%s -\n", e.path)
+			}
+		}
+	}
+
+	return fmt.Sprintf("%016x", h.Sum64())
+}
@@ -0,0 +1,174 @@
+package gitstatus
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gitConfig holds the values read from git config files. Keys are
+// "section.key" or "section.subsection.key" with section and key
+// lowercased; a key set several times keeps every value in order.
+type gitConfig map[string][]string
+
+// get returns the last value of a key, or "" if it is not set
+func (c gitConfig) get(key string) string {
+	values := c[key]
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
+// bool interprets a key as a git boolean
+func (c gitConfig) bool(key string, def bool) bool {
+	values, ok := c[key]
+	if !ok || len(values) == 0 {
+		return def
+	}
+	switch strings.ToLower(values[len(values)-1]) {
+	case "true", "yes", "on", "1":
+		return true
+	case "false", "no", "off", "0", "":
+		return false
+	}
+	return def
+}
+
+// loadGitConfig reads the config git would use for a repository: the
+// global config, unless ExecOptions.IgnoreUserConfig is set, and then the
+// repo's own. include and includeIf directives are not followed.
+func loadGitConfig(commonDir string) gitConfig {
+	cfg := make(gitConfig)
+	if !execOptions.IgnoreUserConfig {
+		for _, path := range globalConfigPaths() {
+			if data, err := os.ReadFile(path); err == nil {
+				parseGitConfig(string(data), cfg)
+			}
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(commonDir, "config")); err == nil {
+		parseGitConfig(string(data), cfg)
+	}
+	return cfg
+}
+
+// globalConfigPaths returns the global config files in the order git
+// reads them
+func globalConfigPaths() []string {
+	if path := os.Getenv("GIT_CONFIG_GLOBAL"); path != "" {
+		return []string{path}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{
+		filepath.Join(xdgConfigHome(home), "git", "config"),
+		filepath.Join(home, ".gitconfig"),
+	}
+}
+
+// xdgConfigHome returns $XDG_CONFIG_HOME, defaulting to ~/.config
+func xdgConfigHome(home string) string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(home, ".config")
+}
+
+// parseGitConfig adds the values of a config file to cfg. Malformed lines
+// are skipped rather than rejected, since git would already have refused
+// to run on a broken config.
+func parseGitConfig(data string, cfg gitConfig) {
+	section := ""
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if line[0] == '[' {
+			end := strings.LastIndexByte(line, ']')
+			if end < 0 {
+				section = ""
+				continue
+			}
+			section = parseSectionHeader(line[1:end])
+			rest := strings.TrimSpace(line[end+1:])
+			if rest == "" || rest[0] == '#' || rest[0] == ';' {
+				continue
+			}
+			// A key may follow the header on the same line
+			line = rest
+		}
+		if section == "" {
+			continue
+		}
+
+		key, value, hasValue := strings.Cut(line, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" {
+			continue
+		}
+		if !hasValue {
+			// A bare key is a boolean true
+			cfg[section+"."+key] = append(cfg[section+"."+key], "true")
+			continue
+		}
+		cfg[section+"."+key] = append(cfg[section+"."+key], parseConfigValue(value))
+	}
+}
+
+// parseSectionHeader turns `section "subsection"` or the legacy
+// `section.subsection` into the key prefix used by gitConfig
+func parseSectionHeader(header string) string {
+	name, sub, hasSub := strings.Cut(strings.TrimSpace(header), " ")
+	if hasSub {
+		sub = strings.TrimSpace(sub)
+		sub = strings.TrimPrefix(sub, `"`)
+		sub = strings.TrimSuffix(sub, `"`)
+		sub = strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(sub)
+		return strings.ToLower(name) + "." + sub
+	}
+	return strings.ToLower(name)
+}
+
+// parseConfigValue strips comments and quotes from a value and resolves
+// its escape sequences
+func parseConfigValue(raw string) string {
+	var b strings.Builder
+	quoted := false
+	pendingSpace := false
+	raw = strings.TrimSpace(raw)
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == '"':
+			quoted = !quoted
+			continue
+		case c == '\\' && i+1 < len(raw):
+			i++
+			switch raw[i] {
+			case 'n':
+				c = '\n'
+			case 't':
+				c = '\t'
+			default:
+				c = raw[i]
+			}
+		case !quoted && (c == '#' || c == ';'):
+			return b.String()
+		case !quoted && (c == ' ' || c == '\t'):
+			// Keep inner whitespace, drop it before a trailing comment
+			pendingSpace = true
+			continue
+		}
+		if pendingSpace && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		pendingSpace = false
+		b.WriteByte(c)
+	}
+	return b.String()
+}
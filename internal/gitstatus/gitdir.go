@@ -0,0 +1,110 @@
+package gitstatus
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Bharath-code/git-scope/internal/model"
+)
+
+// Layout describes where a working tree keeps its git data
+type Layout struct {
+	GitDir    string // Per-worktree git directory (HEAD, index, in-progress state)
+	CommonDir string // Shared git directory (objects, refs, config, stash)
+	Kind      model.RepoKind
+	MainRepo  string // Main working tree, set for linked worktrees only
+}
+
+// ResolveLayout inspects the .git entry of a working tree and resolves
+// both the per-worktree git directory and the shared common directory.
+// A .git directory is a regular repository; a .git file ("gitfile") points
+// to a linked worktree or a submodule checkout. A bare repository is its
+// own git directory.
+func ResolveLayout(repoPath string) (Layout, error) {
+	dotGit := filepath.Join(repoPath, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		if IsBare(repoPath) {
+			return Layout{GitDir: repoPath, CommonDir: repoPath, Kind: model.KindBare}, nil
+		}
+		return Layout{}, err
+	}
+
+	if info.IsDir() {
+		return Layout{GitDir: dotGit, CommonDir: dotGit, Kind: model.KindRepo}, nil
+	}
+
+	gitDir, err := readGitFile(dotGit)
+	if err != nil {
+		return Layout{}, err
+	}
+
+	layout := Layout{
+		GitDir:    gitDir,
+		CommonDir: commonDir(gitDir),
+		Kind:      model.KindRepo,
+	}
+
+	switch {
+	case layout.CommonDir != gitDir:
+		// Linked worktrees keep a "commondir" file pointing back at the
+		// main repository's git directory
+		layout.Kind = model.KindWorktree
+		layout.MainRepo = layout.CommonDir
+		if filepath.Base(layout.CommonDir) == ".git" {
+			layout.MainRepo = filepath.Dir(layout.CommonDir)
+		}
+	case strings.Contains(filepath.ToSlash(gitDir), "/.git/modules/"):
+		// Submodules absorbed into the superproject's .git/modules
+		layout.Kind = model.KindSubmodule
+	}
+
+	return layout, nil
+}
+
+// readGitFile parses a `gitdir: <path>` file and returns the absolute
+// git directory it points to. Relative paths are resolved against the
+// directory containing the gitfile.
+func readGitFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	line := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(line, "gitdir:") {
+		return "", fmt.Errorf("invalid gitfile format: %s", path)
+	}
+
+	gitDir := strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+	gitDir = filepath.Clean(gitDir)
+
+	if info, err := os.Stat(gitDir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("gitdir not found: %s", gitDir)
+	}
+
+	return gitDir, nil
+}
+
+// commonDir returns the shared git directory for a git dir, following
+// the `commondir` file used by linked worktrees
+func commonDir(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+
+	dir := strings.TrimSpace(string(data))
+	if dir == "" {
+		return gitDir
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(gitDir, dir)
+	}
+	return filepath.Clean(dir)
+}
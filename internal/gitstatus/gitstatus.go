@@ -1,8 +1,8 @@
 package gitstatus
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
 	"strconv"
 	"strings"
 	"time"
@@ -10,15 +10,18 @@ import (
 	"github.com/Bharath-code/git-scope/internal/model"
 )
 
-// Status retrieves the git status for a repository at the given path
-func Status(repoPath string) (model.RepoStatus, error) {
+// Status retrieves the git status for a repository at the given path.
+// If ctx ends before every git call finished, the partial status is
+// returned together with the context's error.
+func Status(ctx context.Context, repoPath string) (model.RepoStatus, error) {
 	status := model.RepoStatus{}
 
-	out, err := runGit(repoPath, "status", "--porcelain=v2", "-b")
+	out, err := Git(ctx, repoPath, "status", "--porcelain=v2", "-b")
 	if err != nil {
 		return status, fmt.Errorf("git status: %w", err)
 	}
 
+	subFlags := make(map[string]submoduleFlags)
 	for _, line := range strings.Split(string(out), "\n") {
 		if line == "" {
 			continue
@@ -32,34 +35,75 @@ func Status(repoPath string) (model.RepoStatus, error) {
 
 		// non-header lines -> file status records
 		applyFileLine(&status, line)
+
+		if path, flags, ok := parseSubmoduleField(line); ok {
+			subFlags[path] = flags
+		}
+	}
+
+	applyUpstreamState(&status)
+	if status.NoUpstream || status.UpstreamGone || status.Branch == "(detached)" {
+		if n, err := unpublishedCommits(ctx, repoPath); err == nil {
+			status.Unpublished = n
+		}
+	}
+
+	if branches, err := unpushedBranches(ctx, repoPath); err == nil {
+		status.UnpushedBranches = branches
+	}
+
+	if subs, err := submodules(ctx, repoPath, subFlags); err == nil {
+		status.Submodules = subs
+	}
+
+	if layout, err := ResolveLayout(repoPath); err == nil {
+		status.Operation = detectOperation(layout.GitDir)
+		if stashes, err := readStashes(layout.CommonDir); err == nil {
+			status.Stashes = len(stashes)
+		}
 	}
 
-	status.IsDirty = status.Staged > 0 || status.Unstaged > 0 || status.Untracked > 0 || status.Ahead > 0 || status.Behind > 0
+	status.IsDirty = isDirty(status)
 
-	if t, err := lastCommitTime(repoPath); err == nil {
+	if t, err := lastCommitTime(ctx, repoPath); err == nil {
 		status.LastCommit = t
 	}
 
+	// The optional calls above ignore their errors, so a deadline hit
+	// halfway through has to be reported here
+	if err := ctx.Err(); err != nil {
+		return status, err
+	}
+
 	return status, nil
 }
 
-// runGit is a helper that executes a git command with the given arguments
-// in the specified directory and returns its stdout output
-func runGit(dir string, args ...string) ([]byte, error) {
-	cmd := exec.Command("git", args...)
-	cmd.Dir = dir
-	return cmd.Output()
+// isDirty reports whether a repo has anything to act on: local changes,
+// commits to push or pull, an unfinished operation or submodules that
+// need attention
+func isDirty(s model.RepoStatus) bool {
+	return s.Staged > 0 || s.Unstaged > 0 || s.Untracked > 0 || s.Ahead > 0 || s.Behind > 0 ||
+		s.Unpublished > 0 || s.Conflicts > 0 || s.Operation != "" || submodulesNeedAttention(s.Submodules)
 }
 
 // applyBranchHeader parses porcelain v2 branch metadata lines and updates
-// the repository status with branch name and ahead/behind information
+// the repository status with branch name, upstream and ahead/behind information
 func applyBranchHeader(status *model.RepoStatus, line string) {
 	if strings.HasPrefix(line, "# branch.head ") {
 		status.Branch = strings.TrimPrefix(line, "# branch.head ")
 		return
 	}
 
+	if strings.HasPrefix(line, "# branch.upstream ") {
+		status.Upstream = strings.TrimPrefix(line, "# branch.upstream ")
+		// Git omits branch.ab when the upstream no longer exists, so assume
+		// it is gone until the ahead/behind header proves otherwise
+		status.UpstreamGone = true
+		return
+	}
+
 	if strings.HasPrefix(line, "# branch.ab ") {
+		status.UpstreamGone = false
 		ahead, behind, ok := parseAheadBehind(line)
 		if ok {
 			status.Ahead = ahead
@@ -69,6 +113,22 @@ func applyBranchHeader(status *model.RepoStatus, line string) {
 	}
 }
 
+// applyUpstreamState flags a checked-out branch without a tracking branch.
+// It must run after all branch headers were parsed.
+func applyUpstreamState(status *model.RepoStatus) {
+	status.NoUpstream = status.Upstream == "" && status.Branch != "" && status.Branch != "(detached)"
+}
+
+// unpublishedCommits counts the commits reachable from HEAD that are not
+// present on any remote-tracking branch
+func unpublishedCommits(ctx context.Context, repoPath string) (int, error) {
+	out, err := Git(ctx, repoPath, "rev-list", "--count", "HEAD", "--not", "--remotes")
+	if err != nil {
+		return 0, fmt.Errorf("git rev-list: %w", err)
+	}
+	return strconv.Atoi(strings.TrimSpace(string(out)))
+}
+
 // parseAheadBehind extracts ahead/behind commit counts from a
 // `# branch.ab +N -M` porcelain v2 header lien.
 // It returns ok = false if the line cannot be parsed.
@@ -95,6 +155,7 @@ func applyFileLine(status *model.RepoStatus, line string) {
 	// Porcelain v2 format:
 	// 1 = Changed entries (staged or unstaged)
 	// 2 = Renamed/copied entries
+	// u = Unmerged entries (conflicts)
 	// ? = Untracked files
 	// ! = Ignored files
 
@@ -108,6 +169,9 @@ func applyFileLine(status *model.RepoStatus, line string) {
 			status.Unstaged++
 		}
 
+	case strings.HasPrefix(line, "u "):
+		status.Conflicts++
+
 	case strings.HasPrefix(line, "? "):
 		status.Untracked++
 	}
@@ -131,8 +195,8 @@ func parseXY(line string) (staged bool, unstaged bool) {
 }
 
 // lastCommitTime retrieves the timestamp of the most recent commit
-func lastCommitTime(repoPath string) (time.Time, error) {
-	out, err := runGit(repoPath, "log", "-1", "--format=%ct")
+func lastCommitTime(ctx context.Context, repoPath string) (time.Time, error) {
+	out, err := Git(ctx, repoPath, "log", "-1", "--format=%ct")
 	if err != nil {
 		return time.Time{}, fmt.Errorf("git log: %w", err)
 	}
@@ -0,0 +1,199 @@
+package gitstatus
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignoreRule is a single pattern of a gitignore file
+type ignoreRule struct {
+	re       *regexp.Regexp
+	negate   bool // "!pattern" re-includes what earlier patterns excluded
+	dirOnly  bool // "pattern/" only matches directories
+	basename bool // No slash in the pattern: match the name at any depth
+}
+
+// ignoreList is the rules of one ignore file. Patterns containing a slash
+// are relative to dir, the worktree directory the file applies to.
+type ignoreList struct {
+	dir   string
+	rules []ignoreRule
+}
+
+// ignoreStack holds the ignore lists that apply to a directory, from the
+// least to the most specific: core.excludesFile, info/exclude, then the
+// .gitignore of each directory from the worktree root down
+type ignoreStack []ignoreList
+
+// ignored reports whether a worktree path is ignored. The most specific
+// list with a matching pattern decides, and within a list the last
+// matching pattern does, as in git.
+func (s ignoreStack) ignored(path string, isDir bool) bool {
+	name := path[strings.LastIndexByte(path, '/')+1:]
+	for i := len(s) - 1; i >= 0; i-- {
+		list := s[i]
+		rel := path
+		if list.dir != "" {
+			rel = strings.TrimPrefix(path, list.dir+"/")
+		}
+		for j := len(list.rules) - 1; j >= 0; j-- {
+			r := list.rules[j]
+			if r.dirOnly && !isDir {
+				continue
+			}
+			subject := rel
+			if r.basename {
+				subject = name
+			}
+			if r.re.MatchString(subject) {
+				return !r.negate
+			}
+		}
+	}
+	return false
+}
+
+// withFile returns the stack extended by the rules of an ignore file, or
+// the stack itself if the file does not exist or has no rules
+func (s ignoreStack) withFile(path, dir string) ignoreStack {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s
+	}
+	rules := parseIgnoreRules(string(data))
+	if len(rules) == 0 {
+		return s
+	}
+	// Copy so sibling directories don't share the appended element
+	out := make(ignoreStack, len(s), len(s)+1)
+	copy(out, s)
+	return append(out, ignoreList{dir: dir, rules: rules})
+}
+
+// baseIgnores returns the rules that apply to the whole worktree:
+// core.excludesFile (default ~/.config/git/ignore) and info/exclude
+func baseIgnores(cfg gitConfig, commonDir string) ignoreStack {
+	var stack ignoreStack
+
+	excludesFile := cfg.get("core.excludesfile")
+	if excludesFile == "" && !execOptions.IgnoreUserConfig {
+		if home, err := os.UserHomeDir(); err == nil {
+			excludesFile = filepath.Join(xdgConfigHome(home), "git", "ignore")
+		}
+	}
+	if strings.HasPrefix(excludesFile, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			excludesFile = filepath.Join(home, excludesFile[2:])
+		}
+	}
+	if excludesFile != "" {
+		stack = stack.withFile(excludesFile, "")
+	}
+
+	return stack.withFile(filepath.Join(commonDir, "info", "exclude"), "")
+}
+
+// parseIgnoreRules parses the lines of a gitignore file. Patterns that
+// cannot be compiled are skipped.
+func parseIgnoreRules(data string) []ignoreRule {
+	var rules []ignoreRule
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		// Trailing spaces are ignored unless escaped with a backslash
+		for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+			line = line[:len(line)-1]
+		}
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		var r ignoreRule
+		if line[0] == '!' {
+			r.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		r.basename = !strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+
+		re, err := regexp.Compile(globToRegexp(line))
+		if err != nil {
+			continue
+		}
+		r.re = re
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// globToRegexp translates a gitignore glob to an anchored regexp.
+// "*" and "?" stop at slashes; "**" as a whole path component spans
+// any number of directories.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' && (i == 0 || glob[i-1] == '/') {
+				end := i + 2
+				if end == len(glob) {
+					// Trailing "/**": everything inside
+					b.WriteString(".*")
+					i = end - 1
+					continue
+				}
+				if glob[end] == '/' {
+					// Leading "**/" or inner "/**/": zero or more directories
+					b.WriteString("(?:.*/)?")
+					i = end
+					continue
+				}
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := i + 1
+			if end < len(glob) && (glob[end] == '!' || glob[end] == '^') {
+				end++
+			}
+			if end < len(glob) && glob[end] == ']' {
+				end++
+			}
+			for end < len(glob) && glob[end] != ']' {
+				end++
+			}
+			if end >= len(glob) {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : end]
+			if class[0] == '!' {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i = end
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
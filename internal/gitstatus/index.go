@@ -0,0 +1,309 @@
+package gitstatus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// File modes as stored in the index and in trees
+const (
+	modeFile       = 0o100644
+	modeExecutable = 0o100755
+	modeSymlink    = 0o120000
+	modeGitlink    = 0o160000
+	modeTree       = 0o040000
+	modeTypeMask   = 0o170000
+)
+
+// indexEntry is a single path of the index with the stat data git
+// recorded when it last looked at the worktree file
+type indexEntry struct {
+	path         string
+	id           objectID
+	mode         uint32
+	stage        int // 0 normally, 1-3 for the sides of a conflict
+	mtime        time.Time
+	mtimeNsec    bool // mtime has sub-second precision
+	size         uint32
+	assumeValid  bool
+	skipWorktree bool
+	intentToAdd  bool
+}
+
+// gitIndex is the parsed index of a worktree
+type gitIndex struct {
+	entries []indexEntry
+	// cacheTree maps directories ("" for the root) to the tree object
+	// the index content below them corresponds to, where still known
+	cacheTree map[string]objectID
+	// mtime is the index file's own modification time. Entries modified
+	// at or after it are "racily clean" and need a content check.
+	mtime time.Time
+}
+
+// readIndex parses the index of the worktree whose git directory is
+// gitDir. A missing index is an empty one, as in a fresh `git init`.
+func readIndex(gitDir string) (*gitIndex, error) {
+	path := filepath.Join(gitDir, "index")
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &gitIndex{}, nil
+		}
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := parseIndex(data)
+	if err != nil {
+		return nil, err
+	}
+	idx.mtime = info.ModTime()
+	return idx, nil
+}
+
+// IndexKey summarizes what the index of a worktree records, leaving out
+// the stat data cached for each entry. git status rewrites the index just
+// to refresh that stat data unless run with GIT_OPTIONAL_LOCKS=0, which
+// leaves the key unchanged. An index that cannot be parsed is summarized
+// by its size and modification time instead.
+func IndexKey(gitDir string) string {
+	path := filepath.Join(gitDir, "index")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "-"
+	}
+	idx, err := parseIndex(data)
+	if err != nil {
+		if info, err := os.Stat(path); err == nil {
+			return fmt.Sprintf("stat %d %d", info.Size(), info.ModTime().UnixNano())
+		}
+		return "-"
+	}
+	return idx.key()
+}
+
+// key implements IndexKey for a parsed index
+func (idx *gitIndex) key() string {
+	h := fnv.New64a()
+	for _, e := range idx.entries {
+		fmt.Fprintf(h, "%s %x %o %d %t %t %t\n", e.path, e.id, e.mode, e.stage, e.assumeValid, e.skipWorktree, e.intentToAdd)
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// TrackedDirs returns the worktree directories holding tracked files,
+// slash-separated and relative to the worktree root, which is always
+// included as "". It reads the index of the worktree directly.
+func TrackedDirs(layout Layout) ([]string, error) {
+	idx, err := readIndex(layout.GitDir)
+	if err != nil {
+		return nil, err
+	}
+	return idx.trackedDirs(), nil
+}
+
+// trackedDirs implements TrackedDirs for a parsed index
+func (idx *gitIndex) trackedDirs() []string {
+	seen := map[string]bool{"": true}
+	dirs := []string{""}
+	for _, e := range idx.entries {
+		// Parents are added with their children, so a known directory
+		// means all of its parents are known too
+		for i := strings.LastIndexByte(e.path, '/'); i > 0; i = strings.LastIndexByte(e.path[:i], '/') {
+			if seen[e.path[:i]] {
+				break
+			}
+			seen[e.path[:i]] = true
+			dirs = append(dirs, e.path[:i])
+		}
+	}
+	return dirs
+}
+
+// parseIndex parses index versions 2 to 4
+func parseIndex(data []byte) (*gitIndex, error) {
+	errMalformed := errors.New("malformed index")
+	if len(data) < 12+20 || !bytes.Equal(data[:4], []byte("DIRC")) {
+		return nil, errMalformed
+	}
+	version := binary.BigEndian.Uint32(data[4:8])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("index version %d: %w", version, errUnsupported)
+	}
+	count := int(binary.BigEndian.Uint32(data[8:12]))
+
+	// Drop the trailing checksum so extensions can't run into it
+	body := data[12 : len(data)-20]
+	idx := &gitIndex{entries: make([]indexEntry, 0, count)}
+
+	prevPath := ""
+	for i := 0; i < count; i++ {
+		const statSize = 62 // 10 stat fields, object id and flags
+		if len(body) < statSize {
+			return nil, errMalformed
+		}
+		u32 := func(off int) uint32 { return binary.BigEndian.Uint32(body[off : off+4]) }
+
+		e := indexEntry{
+			mode: u32(24),
+			size: u32(36),
+		}
+		mtimeNsec := u32(12)
+		e.mtime = time.Unix(int64(u32(8)), int64(mtimeNsec))
+		e.mtimeNsec = mtimeNsec != 0
+		copy(e.id[:], body[40:60])
+		flags := binary.BigEndian.Uint16(body[60:62])
+		e.assumeValid = flags&0x8000 != 0
+		e.stage = int(flags>>12) & 3
+		nameLen := int(flags & 0x0fff)
+
+		off := statSize
+		if flags&0x4000 != 0 {
+			// Extended flags, index version 3 and later
+			if version < 3 || len(body) < off+2 {
+				return nil, errMalformed
+			}
+			ext := binary.BigEndian.Uint16(body[off : off+2])
+			e.skipWorktree = ext&0x4000 != 0
+			e.intentToAdd = ext&0x2000 != 0
+			off += 2
+		}
+
+		if version == 4 {
+			// The path is stored as the number of bytes to drop from the
+			// end of the previous path, then the NUL-terminated suffix
+			strip, n := indexVarint(body[off:])
+			if n == 0 || strip > len(prevPath) {
+				return nil, errMalformed
+			}
+			off += n
+			nul := bytes.IndexByte(body[off:], 0)
+			if nul < 0 {
+				return nil, errMalformed
+			}
+			e.path = prevPath[:len(prevPath)-strip] + string(body[off:off+nul])
+			body = body[off+nul+1:]
+		} else {
+			// NUL padding brings each entry to a multiple of 8 bytes
+			nul := bytes.IndexByte(body[off:], 0)
+			if nul < 0 {
+				return nil, errMalformed
+			}
+			if nameLen < 0x0fff && nul != nameLen {
+				return nil, errMalformed
+			}
+			e.path = string(body[off : off+nul])
+			size := (off + nul + 8) &^ 7
+			if size > len(body) {
+				return nil, errMalformed
+			}
+			body = body[size:]
+		}
+
+		prevPath = e.path
+		idx.entries = append(idx.entries, e)
+	}
+
+	// Extensions: a 4 byte signature and a 4 byte size each
+	for len(body) >= 8 {
+		sig := string(body[:4])
+		size := int(binary.BigEndian.Uint32(body[4:8]))
+		if 8+size > len(body) {
+			return nil, errMalformed
+		}
+		ext := body[8 : 8+size]
+		body = body[8+size:]
+
+		switch sig {
+		case "TREE":
+			idx.cacheTree = make(map[string]objectID)
+			if _, err := parseCacheTree(ext, "", idx.cacheTree); err != nil {
+				// The cache tree only speeds things up; do without it
+				idx.cacheTree = nil
+			}
+		case "link":
+			return nil, fmt.Errorf("split index: %w", errUnsupported)
+		case "sdir":
+			return nil, fmt.Errorf("sparse index: %w", errUnsupported)
+		}
+	}
+
+	return idx, nil
+}
+
+// parseCacheTree parses one node of the TREE extension and its subtrees:
+// `<name>\0<entries> <subtrees>\n`, then the tree id if entries >= 0.
+// Nodes with entries = -1 were invalidated and have no id.
+func parseCacheTree(data []byte, parent string, out map[string]objectID) ([]byte, error) {
+	errMalformed := errors.New("malformed cache tree")
+
+	nul := bytes.IndexByte(data, 0)
+	if nul < 0 {
+		return nil, errMalformed
+	}
+	path := string(data[:nul])
+	if parent != "" {
+		path = parent + "/" + path
+	}
+	data = data[nul+1:]
+
+	nl := bytes.IndexByte(data, '\n')
+	if nl < 0 {
+		return nil, errMalformed
+	}
+	fields := strings.Fields(string(data[:nl]))
+	data = data[nl+1:]
+	if len(fields) != 2 {
+		return nil, errMalformed
+	}
+	entries, err1 := strconv.Atoi(fields[0])
+	subtrees, err2 := strconv.Atoi(fields[1])
+	if err1 != nil || err2 != nil {
+		return nil, errMalformed
+	}
+
+	if entries >= 0 {
+		if len(data) < 20 {
+			return nil, errMalformed
+		}
+		var id objectID
+		copy(id[:], data[:20])
+		out[path] = id
+		data = data[20:]
+	}
+
+	var err error
+	for i := 0; i < subtrees; i++ {
+		if data, err = parseCacheTree(data, path, out); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// indexVarint decodes the offset varint of index version 4 paths. It
+// returns n = 0 if the data ends early.
+func indexVarint(data []byte) (value int, n int) {
+	for i, c := range data {
+		if i > 0 {
+			value++
+		}
+		value = value<<7 | int(c&0x7f)
+		if c&0x80 == 0 {
+			return value, i + 1
+		}
+	}
+	return 0, 0
+}
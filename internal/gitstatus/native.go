@@ -0,0 +1,930 @@
+package gitstatus
+
+import (
+	"container/heap"
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Bharath-code/git-scope/internal/model"
+)
+
+// nativeRepo reads the state of a repository straight from its files
+type nativeRepo struct {
+	path    string
+	layout  Layout
+	config  gitConfig
+	refs    *refStore
+	objects *objectStore
+	shallow map[objectID]bool
+	commits map[objectID]commit
+	remotes []objectID // Tips of refs/remotes, loaded on first use
+}
+
+// openNativeRepo opens the repository of a working tree. Repositories
+// owned by another user or using an object or ref format other than
+// SHA-1 files are reported as errUnsupported.
+func openNativeRepo(repoPath string) (*nativeRepo, error) {
+	layout, err := ResolveLayout(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	// Let git apply safe.directory and report dubious ownership
+	if ownedByOther(repoPath) || ownedByOther(layout.GitDir) {
+		return nil, fmt.Errorf("repository owned by another user: %w", errUnsupported)
+	}
+
+	cfg := loadGitConfig(layout.CommonDir)
+	if cfg.bool("extensions.worktreeconfig", false) {
+		if data, err := os.ReadFile(filepath.Join(layout.GitDir, "config.worktree")); err == nil {
+			parseGitConfig(string(data), cfg)
+		}
+	}
+	if f := cfg.get("extensions.objectformat"); f != "" && !strings.EqualFold(f, "sha1") {
+		return nil, fmt.Errorf("object format %s: %w", f, errUnsupported)
+	}
+	if s := cfg.get("extensions.refstorage"); s != "" && !strings.EqualFold(s, "files") {
+		return nil, fmt.Errorf("ref storage %s: %w", s, errUnsupported)
+	}
+
+	shallow, err := readShallow(layout.CommonDir)
+	if err != nil {
+		return nil, fmt.Errorf("read shallow: %w", err)
+	}
+
+	return &nativeRepo{
+		path:    repoPath,
+		layout:  layout,
+		config:  cfg,
+		refs:    &refStore{gitDir: layout.GitDir, commonDir: layout.CommonDir},
+		objects: openObjectStore(layout.CommonDir),
+		shallow: shallow,
+		commits: make(map[objectID]commit),
+	}, nil
+}
+
+func (r *nativeRepo) close() {
+	r.objects.close()
+}
+
+// commit reads a commit through a per-repo cache, since history walks
+// visit the same commits from several tips
+func (r *nativeRepo) commit(id objectID) (commit, error) {
+	if c, ok := r.commits[id]; ok {
+		return c, nil
+	}
+	c, err := r.objects.readCommit(id)
+	if err != nil {
+		return c, err
+	}
+	if r.shallow[id] {
+		c.parents = nil
+	}
+	r.commits[id] = c
+	return c, nil
+}
+
+// nativeSubmodule is the state of a gitlink found while comparing the index
+type nativeSubmodule struct {
+	initialized bool
+	head        objectID // Checked out commit, if initialized
+	flags       submoduleFlags
+}
+
+// nativeStatus collects the same status as Status without running git
+func nativeStatus(ctx context.Context, repoPath string) (model.RepoStatus, error) {
+	status := model.RepoStatus{}
+
+	r, err := openNativeRepo(repoPath)
+	if err != nil {
+		return status, err
+	}
+	defer r.close()
+
+	branch, head, hasHead, err := r.refs.head()
+	if err != nil {
+		return status, fmt.Errorf("read HEAD: %w", err)
+	}
+	status.Branch = branch
+	if branch == "" {
+		status.Branch = "(detached)"
+	}
+
+	var headTree objectID
+	if hasHead {
+		c, err := r.commit(head)
+		if err != nil {
+			return status, err
+		}
+		headTree = c.tree
+		status.LastCommit = time.Unix(c.time, 0)
+	}
+
+	idx, err := readIndex(r.layout.GitDir)
+	if err != nil {
+		return status, fmt.Errorf("read index: %w", err)
+	}
+
+	if status.Staged, err = r.countStaged(ctx, idx, headTree, hasHead); err != nil {
+		return status, err
+	}
+	subs, err := r.compareWorktree(ctx, idx, &status)
+	if err != nil {
+		return status, err
+	}
+	if status.Untracked, err = r.countUntracked(ctx, idx, false); err != nil {
+		return status, err
+	}
+
+	if branch != "" {
+		if err := r.applyUpstream(ctx, branch, head, hasHead, &status); err != nil {
+			return status, err
+		}
+	}
+	applyUpstreamState(&status)
+	if hasHead && (status.NoUpstream || status.UpstreamGone || status.Branch == "(detached)") {
+		remotes, err := r.remoteTips()
+		if err != nil {
+			return status, err
+		}
+		if status.Unpublished, _, err = r.divergence(ctx, []objectID{head}, remotes); err != nil {
+			return status, err
+		}
+	}
+
+	if status.UnpushedBranches, err = r.unpushedBranches(ctx); err != nil {
+		return status, err
+	}
+	status.Submodules = r.submodules(idx, subs)
+
+	status.Operation = detectOperation(r.layout.GitDir)
+	if stashes, err := readStashes(r.layout.CommonDir); err == nil {
+		status.Stashes = len(stashes)
+	}
+
+	status.IsDirty = isDirty(status)
+
+	if err := ctx.Err(); err != nil {
+		return status, err
+	}
+	return status, nil
+}
+
+// countStaged counts the paths whose index entry differs from HEAD, the
+// X side of `git status`. Renames are only paired when the content is
+// unchanged, while git also pairs similar files.
+func (r *nativeRepo) countStaged(ctx context.Context, idx *gitIndex, headTree objectID, hasHead bool) (int, error) {
+	// A valid cache tree that matches HEAD proves nothing is staged
+	if root, ok := idx.cacheTree[""]; ok && hasHead && root == headTree {
+		return 0, nil
+	}
+
+	head := make(map[string]treeEntry)
+	unchangedDirs := make(map[string]bool)
+	if hasHead {
+		if err := r.flattenTree(ctx, headTree, "", idx.cacheTree, head, unchangedDirs); err != nil {
+			return 0, err
+		}
+	}
+
+	var modified int
+	added := make(map[objectID]int)
+	conflicted := make(map[string]bool)
+	for _, e := range idx.entries {
+		if e.stage != 0 {
+			conflicted[e.path] = true
+			continue
+		}
+		// Intent-to-add entries only show up on the worktree side
+		if e.intentToAdd || inUnchangedDir(e.path, unchangedDirs) {
+			continue
+		}
+		he, ok := head[e.path]
+		if !ok {
+			added[e.id]++
+			continue
+		}
+		delete(head, e.path)
+		if he.id != e.id || he.mode != e.mode {
+			modified++
+		}
+	}
+
+	count := modified
+	for _, n := range added {
+		count += n
+	}
+	for path, he := range head {
+		if conflicted[path] {
+			continue
+		}
+		if added[he.id] > 0 {
+			// Deleted and re-added with the same content: one rename
+			added[he.id]--
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// flattenTree lists the blobs of a tree by path. Directories whose tree
+// matches the index cache tree are recorded in unchanged instead of read.
+func (r *nativeRepo) flattenTree(ctx context.Context, tree objectID, prefix string, cacheTree map[string]objectID, out map[string]treeEntry, unchanged map[string]bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	entries, err := r.objects.readTree(tree)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		path := e.name
+		if prefix != "" {
+			path = prefix + "/" + e.name
+		}
+		if e.mode&modeTypeMask != modeTree {
+			out[path] = e
+			continue
+		}
+		if id, ok := cacheTree[path]; ok && id == e.id {
+			unchanged[path] = true
+			continue
+		}
+		if err := r.flattenTree(ctx, e.id, path, cacheTree, out, unchanged); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inUnchangedDir reports whether path lies below one of the directories
+func inUnchangedDir(path string, dirs map[string]bool) bool {
+	if len(dirs) == 0 {
+		return false
+	}
+	for i := strings.LastIndexByte(path, '/'); i > 0; i = strings.LastIndexByte(path[:i], '/') {
+		if dirs[path[:i]] {
+			return true
+		}
+	}
+	return false
+}
+
+// compareWorktree counts conflicts and the paths whose worktree file
+// differs from the index, the Y side of `git status`. It returns the
+// state of every submodule checkout it compared.
+func (r *nativeRepo) compareWorktree(ctx context.Context, idx *gitIndex, status *model.RepoStatus) (map[string]nativeSubmodule, error) {
+	subs := make(map[string]nativeSubmodule)
+	conflicted := make(map[string]bool)
+	fileMode := r.config.bool("core.filemode", true)
+	symlinks := r.config.bool("core.symlinks", true)
+
+	for i, e := range idx.entries {
+		if i%256 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		if e.stage != 0 {
+			if !conflicted[e.path] {
+				conflicted[e.path] = true
+				status.Conflicts++
+			}
+			continue
+		}
+		if e.skipWorktree {
+			continue
+		}
+		if e.intentToAdd {
+			status.Unstaged++
+			continue
+		}
+
+		if e.mode == modeGitlink {
+			sub, err := r.submoduleState(ctx, e)
+			if err != nil {
+				return nil, err
+			}
+			subs[e.path] = sub
+			if sub.flags.commitChanged || sub.flags.modified || sub.flags.untracked {
+				status.Unstaged++
+			}
+			continue
+		}
+
+		changed, err := r.worktreeChanged(e, idx.mtime, fileMode, symlinks)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			status.Unstaged++
+		}
+	}
+	return subs, nil
+}
+
+// worktreeChanged compares a worktree file with its index entry. Like
+// git, it trusts matching stat data and only hashes the file when the
+// timestamps changed but the size did not, or when the entry is racily
+// clean.
+func (r *nativeRepo) worktreeChanged(e indexEntry, indexMtime time.Time, fileMode, symlinks bool) (bool, error) {
+	// Index paths are clean, so skip filepath.Join's cleaning; this runs
+	// once per tracked file
+	path := r.path + string(filepath.Separator) + filepath.FromSlash(e.path)
+	info, err := os.Lstat(path)
+	if err != nil {
+		// Deleted, or a parent directory was replaced by a file
+		return true, nil
+	}
+	if e.assumeValid {
+		return false, nil
+	}
+
+	isLink := info.Mode()&os.ModeSymlink != 0
+	switch {
+	case e.mode == modeSymlink && !symlinks && info.Mode().IsRegular():
+		// Symlinks checked out as plain files holding the target
+		isLink = false
+	case e.mode == modeSymlink:
+		if !isLink {
+			return true, nil
+		}
+	case !info.Mode().IsRegular():
+		return true, nil
+	case fileMode && (info.Mode()&0o111 != 0) != (e.mode == modeExecutable):
+		return true, nil
+	}
+
+	size := uint32(info.Size())
+	mtime := info.ModTime()
+	sameTime := mtime.Unix() == e.mtime.Unix() && (!e.mtimeNsec || mtime.Nanosecond() == e.mtime.Nanosecond())
+	racy := !e.mtime.Before(indexMtime)
+	if size == e.size && sameTime && !racy {
+		return false, nil
+	}
+	// A size of 0 in the index means git never recorded a trustworthy
+	// size for the entry, so only a recorded size can prove a change
+	if size != e.size && e.size != 0 {
+		return true, nil
+	}
+
+	id, err := hashWorktreeBlob(path, info.Size(), isLink)
+	if err != nil {
+		return true, nil
+	}
+	if id == e.id {
+		return false, nil
+	}
+	// Line ending conversion or clean filters can make an unchanged
+	// file hash differently; let git decide
+	if r.mayConvert() {
+		return false, fmt.Errorf("content filters: %w", errUnsupported)
+	}
+	return true, nil
+}
+
+// mayConvert reports whether git could transform file content between
+// the worktree and the index. Only the common places for attributes
+// are checked; nested .gitattributes files are not.
+func (r *nativeRepo) mayConvert() bool {
+	switch strings.ToLower(r.config.get("core.autocrlf")) {
+	case "true", "input", "yes", "on", "1":
+		return true
+	}
+	if r.config.get("core.attributesfile") != "" {
+		return true
+	}
+	for _, path := range []string{
+		filepath.Join(r.path, ".gitattributes"),
+		filepath.Join(r.layout.CommonDir, "info", "attributes"),
+	} {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// hashBuffers recycles the read buffers of hashWorktreeBlob, which runs
+// for every racily clean file
+var hashBuffers = sync.Pool{New: func() interface{} {
+	buf := make([]byte, 32*1024)
+	return &buf
+}}
+
+// hashWorktreeBlob computes the blob id a worktree file would get. For
+// symlinks the content is the link target.
+func hashWorktreeBlob(path string, size int64, isLink bool) (objectID, error) {
+	var id objectID
+	h := sha1.New()
+
+	if isLink {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return id, err
+		}
+		fmt.Fprintf(h, "blob %d\x00%s", len(target), target)
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return id, err
+		}
+		defer f.Close()
+		fmt.Fprintf(h, "blob %d\x00", size)
+		buf := hashBuffers.Get().(*[]byte)
+		defer hashBuffers.Put(buf)
+		// Hide the file's WriteTo so CopyBuffer uses our buffer
+		if _, err := io.CopyBuffer(h, struct{ io.Reader }{f}, *buf); err != nil {
+			return id, err
+		}
+	}
+
+	copy(id[:], h.Sum(nil))
+	return id, nil
+}
+
+// submoduleState compares a submodule checkout with the commit recorded
+// in the superproject and looks for changes inside it
+func (r *nativeRepo) submoduleState(ctx context.Context, e indexEntry) (nativeSubmodule, error) {
+	var sub nativeSubmodule
+	subPath := filepath.Join(r.path, filepath.FromSlash(e.path))
+	if _, err := os.Stat(filepath.Join(subPath, ".git")); err != nil {
+		// Not checked out; git status does not report it
+		return sub, nil
+	}
+
+	s, err := openNativeRepo(subPath)
+	if err != nil {
+		return sub, err
+	}
+	defer s.close()
+	sub.initialized = true
+
+	_, head, hasHead, err := s.refs.head()
+	if err != nil {
+		return sub, fmt.Errorf("read HEAD of submodule %s: %w", e.path, err)
+	}
+	sub.head = head
+	sub.flags.commitChanged = !hasHead || head != e.id
+
+	var headTree objectID
+	if hasHead {
+		c, err := s.commit(head)
+		if err != nil {
+			return sub, err
+		}
+		headTree = c.tree
+	}
+	idx, err := readIndex(s.layout.GitDir)
+	if err != nil {
+		return sub, fmt.Errorf("read index of submodule %s: %w", e.path, err)
+	}
+
+	var inner model.RepoStatus
+	if inner.Staged, err = s.countStaged(ctx, idx, headTree, hasHead); err != nil {
+		return sub, err
+	}
+	if _, err := s.compareWorktree(ctx, idx, &inner); err != nil {
+		return sub, err
+	}
+	sub.flags.modified = inner.Staged > 0 || inner.Unstaged > 0 || inner.Conflicts > 0
+	untracked, err := s.countUntracked(ctx, idx, true)
+	if err != nil {
+		return sub, err
+	}
+	sub.flags.untracked = untracked > 0
+	return sub, nil
+}
+
+// submodules lists the gitlinks of the index the way `git submodule
+// status` does. Like the exec backend it only looks for them when the
+// repo has a .gitmodules file.
+func (r *nativeRepo) submodules(idx *gitIndex, states map[string]nativeSubmodule) []model.Submodule {
+	if _, err := os.Stat(filepath.Join(r.path, ".gitmodules")); err != nil {
+		return nil
+	}
+
+	var subs []model.Submodule
+	seen := make(map[string]bool)
+	for _, e := range idx.entries {
+		if e.mode != modeGitlink || seen[e.path] {
+			continue
+		}
+		seen[e.path] = true
+
+		sub := model.Submodule{Path: e.path, Commit: e.id.String()}
+		state, ok := states[e.path]
+		switch {
+		case e.stage != 0:
+			// Conflicting gitlink
+			sub.OutOfSync = true
+		case !ok || !state.initialized:
+			sub.Uninitialized = true
+		default:
+			sub.Commit = state.head.String()
+			sub.OutOfSync = state.flags.commitChanged
+			sub.Dirty = state.flags.modified || state.flags.untracked
+		}
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+// countUntracked counts untracked paths the way `git status` lists them.
+// In the default "normal" mode a directory without tracked files counts
+// once if it holds anything that is not ignored. With stopAtFirst set it
+// returns as soon as one is found.
+func (r *nativeRepo) countUntracked(ctx context.Context, idx *gitIndex, stopAtFirst bool) (int, error) {
+	mode := strings.ToLower(r.config.get("status.showuntrackedfiles"))
+	if mode == "no" || mode == "false" {
+		return 0, nil
+	}
+
+	w := &untrackedWalk{
+		ctx:         ctx,
+		root:        r.path,
+		all:         mode == "all",
+		stopAtFirst: stopAtFirst,
+		tracked:     make(map[string]bool, len(idx.entries)),
+		trackedDirs: make(map[string]bool),
+	}
+	for _, e := range idx.entries {
+		w.tracked[e.path] = true
+		// Parents are added with their children, so a known directory
+		// means all of its parents are known too
+		for i := strings.LastIndexByte(e.path, '/'); i > 0; i = strings.LastIndexByte(e.path[:i], '/') {
+			if w.trackedDirs[e.path[:i]] {
+				break
+			}
+			w.trackedDirs[e.path[:i]] = true
+		}
+	}
+
+	return w.dir("", baseIgnores(r.config, r.layout.CommonDir))
+}
+
+// untrackedWalk walks a worktree looking for untracked paths
+type untrackedWalk struct {
+	ctx         context.Context
+	root        string
+	all         bool // status.showUntrackedFiles=all: count files, not directories
+	stopAtFirst bool
+	tracked     map[string]bool
+	trackedDirs map[string]bool
+}
+
+// dir counts the untracked paths below a worktree directory
+func (w *untrackedWalk) dir(rel string, ignores ignoreStack) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	abs := filepath.Join(w.root, filepath.FromSlash(rel))
+	ignores = ignores.withFile(filepath.Join(abs, ".gitignore"), rel)
+	entries, err := os.ReadDir(abs)
+	if err != nil {
+		// Unreadable directories are skipped, as git does
+		return 0, nil
+	}
+
+	count := 0
+	for _, d := range entries {
+		if w.stopAtFirst && count > 0 {
+			break
+		}
+		name := d.Name()
+		if name == ".git" {
+			continue
+		}
+		path := name
+		if rel != "" {
+			path = rel + "/" + name
+		}
+		if w.tracked[path] {
+			continue
+		}
+		isDir := d.IsDir()
+		if ignores.ignored(path, isDir) {
+			continue
+		}
+
+		switch {
+		case !isDir:
+			count++
+		case w.trackedDirs[path]:
+			n, err := w.dir(path, ignores)
+			if err != nil {
+				return 0, err
+			}
+			count += n
+		case isNestedRepo(filepath.Join(abs, name)):
+			// git lists a nested repository as a single entry
+			count++
+		case w.all:
+			n, err := w.dir(path, ignores)
+			if err != nil {
+				return 0, err
+			}
+			count += n
+		default:
+			found, err := w.hasContent(path, ignores)
+			if err != nil {
+				return 0, err
+			}
+			if found {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+// hasContent reports whether an untracked directory holds anything git
+// would list; directories with only ignored files are hidden
+func (w *untrackedWalk) hasContent(rel string, ignores ignoreStack) (bool, error) {
+	if err := w.ctx.Err(); err != nil {
+		return false, err
+	}
+
+	abs := filepath.Join(w.root, filepath.FromSlash(rel))
+	ignores = ignores.withFile(filepath.Join(abs, ".gitignore"), rel)
+	entries, err := os.ReadDir(abs)
+	if err != nil {
+		return false, nil
+	}
+
+	for _, d := range entries {
+		path := rel + "/" + d.Name()
+		isDir := d.IsDir()
+		if ignores.ignored(path, isDir) {
+			continue
+		}
+		if !isDir || isNestedRepo(filepath.Join(abs, d.Name())) {
+			return true, nil
+		}
+		found, err := w.hasContent(path, ignores)
+		if err != nil || found {
+			return found, err
+		}
+	}
+	return false, nil
+}
+
+// isNestedRepo reports whether a directory is the root of another
+// working tree
+func isNestedRepo(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// applyUpstream fills in the tracking branch of the checked-out branch
+// and how far HEAD and it have diverged
+func (r *nativeRepo) applyUpstream(ctx context.Context, branch string, head objectID, hasHead bool, status *model.RepoStatus) error {
+	name, ref, ok := r.upstreamOf(branch)
+	if !ok {
+		return nil
+	}
+	status.Upstream = name
+
+	upstream, exists, err := r.refs.resolve(ref)
+	if err != nil {
+		return err
+	}
+	// git reports no ahead/behind counts for a deleted upstream or a
+	// branch without commits
+	if !exists || !hasHead {
+		status.UpstreamGone = true
+		return nil
+	}
+
+	status.Ahead, status.Behind, err = r.divergence(ctx, []objectID{head}, []objectID{upstream})
+	return err
+}
+
+// upstreamOf returns the display name and the ref of a branch's upstream
+// from branch.<name>.remote and branch.<name>.merge, mapped through the
+// remote's fetch refspecs
+func (r *nativeRepo) upstreamOf(branch string) (name, ref string, ok bool) {
+	remote := r.config.get("branch." + branch + ".remote")
+	merge := r.config.get("branch." + branch + ".merge")
+	if remote == "" || merge == "" {
+		return "", "", false
+	}
+	if remote == "." {
+		return shortRefName(merge), merge, true
+	}
+
+	for _, spec := range r.config["remote."+remote+".fetch"] {
+		if dst, ok := mapRefspec(spec, merge); ok {
+			return shortRefName(dst), dst, true
+		}
+	}
+	return "", "", false
+}
+
+// mapRefspec maps a remote ref to its local ref through a fetch refspec
+// such as "+refs/heads/*:refs/remotes/origin/*"
+func mapRefspec(spec, ref string) (string, bool) {
+	spec = strings.TrimPrefix(spec, "+")
+	if strings.HasPrefix(spec, "^") {
+		return "", false
+	}
+	src, dst, ok := strings.Cut(spec, ":")
+	if !ok || dst == "" {
+		return "", false
+	}
+
+	prefix, suffix, wildcard := strings.Cut(src, "*")
+	if !wildcard {
+		return dst, src == ref
+	}
+	if !strings.HasPrefix(ref, prefix) || !strings.HasSuffix(ref, suffix) || len(ref) < len(prefix)+len(suffix) {
+		return "", false
+	}
+	match := ref[len(prefix) : len(ref)-len(suffix)]
+	return strings.Replace(dst, "*", match, 1), true
+}
+
+// shortRefName strips the namespace of a branch or remote-tracking ref
+func shortRefName(ref string) string {
+	for _, prefix := range []string{"refs/heads/", "refs/remotes/", "refs/tags/"} {
+		if strings.HasPrefix(ref, prefix) {
+			return ref[len(prefix):]
+		}
+	}
+	return ref
+}
+
+// remoteTips returns the commits of all remote-tracking branches
+func (r *nativeRepo) remoteTips() ([]objectID, error) {
+	if r.remotes != nil {
+		return r.remotes, nil
+	}
+	refs, err := r.refs.list("refs/remotes/")
+	if err != nil {
+		return nil, err
+	}
+	r.remotes = make([]objectID, 0, len(refs))
+	for _, id := range refs {
+		r.remotes = append(r.remotes, id)
+	}
+	return r.remotes, nil
+}
+
+// unpushedBranches lists local branches with commits that are not on any
+// remote-tracking branch. One walk over all branches answers the common
+// case where everything is pushed.
+func (r *nativeRepo) unpushedBranches(ctx context.Context) ([]model.UnpushedBranch, error) {
+	branches, err := r.refs.list("refs/heads/")
+	if err != nil {
+		return nil, err
+	}
+	if len(branches) == 0 {
+		return nil, nil
+	}
+	remotes, err := r.remoteTips()
+	if err != nil {
+		return nil, err
+	}
+
+	tips := make([]objectID, 0, len(branches))
+	for _, id := range branches {
+		tips = append(tips, id)
+	}
+	if n, _, err := r.divergence(ctx, tips, remotes); err != nil || n == 0 {
+		return nil, err
+	}
+
+	var result []model.UnpushedBranch
+	for ref, id := range branches {
+		n, _, err := r.divergence(ctx, []objectID{id}, remotes)
+		if err != nil {
+			return nil, err
+		}
+		if n > 0 {
+			result = append(result, model.UnpushedBranch{Name: strings.TrimPrefix(ref, "refs/heads/"), Commits: n})
+		}
+	}
+	// Match the ref order of `git for-each-ref`
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// Flags of the history walk in divergence
+const (
+	fromLeft  uint8 = 1
+	fromRight uint8 = 2
+	fromBoth        = fromLeft | fromRight
+)
+
+// divergence counts the commits reachable from left but not from right,
+// and the other way round. It walks both sides newest first and stops
+// once every commit left to visit is reachable from both, which is how
+// git limits its own ahead/behind walk.
+func (r *nativeRepo) divergence(ctx context.Context, left, right []objectID) (onlyLeft, onlyRight int, err error) {
+	flags := make(map[objectID]uint8)
+	queued := make(map[objectID]bool)
+	queue := &commitQueue{}
+	interesting := 0 // Queued commits not yet known to be reachable from both
+
+	mark := func(id objectID, f uint8) error {
+		old := flags[id]
+		updated := old | f
+		if updated == old {
+			return nil
+		}
+		flags[id] = updated
+		if queued[id] {
+			if updated == fromBoth {
+				interesting--
+			}
+			return nil
+		}
+		// New, or already expanded and now reachable from the other
+		// side too: (re)visit so its ancestors learn about it
+		c, err := r.commit(id)
+		if err != nil {
+			return err
+		}
+		heap.Push(queue, queuedCommit{id: id, time: c.time})
+		queued[id] = true
+		if updated != fromBoth {
+			interesting++
+		}
+		return nil
+	}
+
+	for _, id := range left {
+		if err := mark(id, fromLeft); err != nil {
+			return 0, 0, err
+		}
+	}
+	for _, id := range right {
+		if err := mark(id, fromRight); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	for steps := 0; interesting > 0; steps++ {
+		if steps%1024 == 0 {
+			if err := ctx.Err(); err != nil {
+				return 0, 0, err
+			}
+		}
+		item := heap.Pop(queue).(queuedCommit)
+		queued[item.id] = false
+		f := flags[item.id]
+		if f != fromBoth {
+			interesting--
+		}
+		c, err := r.commit(item.id)
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, p := range c.parents {
+			if err := mark(p, f); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+
+	for _, f := range flags {
+		switch f {
+		case fromLeft:
+			onlyLeft++
+		case fromRight:
+			onlyRight++
+		}
+	}
+	return onlyLeft, onlyRight, nil
+}
+
+// queuedCommit is an entry of commitQueue
+type queuedCommit struct {
+	id   objectID
+	time int64
+}
+
+// commitQueue is a max-heap of commits by committer time
+type commitQueue []queuedCommit
+
+func (q commitQueue) Len() int            { return len(q) }
+func (q commitQueue) Less(i, j int) bool  { return q[i].time > q[j].time }
+func (q commitQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *commitQueue) Push(x interface{}) { *q = append(*q, x.(queuedCommit)) }
+func (q *commitQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
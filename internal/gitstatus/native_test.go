@@ -0,0 +1,264 @@
+package gitstatus
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// fixture builds git repositories for tests by running git directly
+type fixture struct {
+	tb  testing.TB
+	dir string
+}
+
+func newFixture(tb testing.TB) *fixture {
+	tb.Helper()
+	if runtime.GOOS == "windows" {
+		tb.Skip("fixtures use POSIX file modes and symlinks")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		tb.Skip("git not installed")
+	}
+	return &fixture{tb: tb, dir: tb.TempDir()}
+}
+
+// git runs a git command in a directory below the fixture root
+func (f *fixture) git(dir string, args ...string) {
+	f.tb.Helper()
+	if out, err := f.run(dir, args...); err != nil {
+		f.tb.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// run runs a git command that is allowed to fail
+func (f *fixture) run(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-c", "protocol.file.allow=always", "-c", "init.defaultBranch=main"}, args...)...)
+	cmd.Dir = filepath.Join(f.dir, dir)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	return cmd.CombinedOutput()
+}
+
+// write creates or replaces a file below the fixture root
+func (f *fixture) write(path, content string) {
+	f.tb.Helper()
+	full := filepath.Join(f.dir, path)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		f.tb.Fatal(err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+		f.tb.Fatal(err)
+	}
+}
+
+// cloneWithUpstream creates a bare remote with one commit and returns
+// the path of a clone of it tracking origin/main
+func (f *fixture) cloneWithUpstream(files int) string {
+	f.tb.Helper()
+	f.git("", "init", "-q", "seed")
+	for i := 0; i < files; i++ {
+		f.write(fmt.Sprintf("seed/dir%d/file%d.txt", i%10, i), fmt.Sprintf("content %d\n", i))
+	}
+	f.write("seed/.gitignore", "*.log\nbuild/\n")
+	f.git("seed", "add", "-A")
+	f.git("seed", "commit", "-q", "-m", "initial")
+	f.git("", "clone", "-q", "--bare", "seed", "remote.git")
+	f.git("", "clone", "-q", "remote.git", "work")
+	return "work"
+}
+
+// assertParity checks that both providers report the same status
+func assertParity(t *testing.T, repo, step string) {
+	t.Helper()
+	ctx := context.Background()
+	want, werr := ExecProvider{}.Status(ctx, repo)
+	got, gerr := nativeStatus(ctx, repo)
+	if werr != nil || gerr != nil {
+		t.Fatalf("%s: exec error %v, native error %v", step, werr, gerr)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s: native status differs from git\n got: %+v\nwant: %+v", step, got, want)
+	}
+}
+
+// TestNativeMatchesExec runs both backends on a repo as it goes through
+// the states git-scope reports on
+func TestNativeMatchesExec(t *testing.T) {
+	f := newFixture(t)
+	work := f.cloneWithUpstream(20)
+	repo := filepath.Join(f.dir, work)
+	assertParity(t, repo, "fresh clone")
+
+	f.git(work, "gc", "-q")
+	assertParity(t, repo, "packed")
+
+	f.write(work+"/dir1/file1.txt", "changed size\n")
+	f.write(work+"/dir2/file2.txt", "content X\n") // Same size as before
+	if err := os.Remove(filepath.Join(repo, "dir3", "file3.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(repo, "dir4", "file4.txt"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	assertParity(t, repo, "worktree changes")
+
+	f.write(work+"/new.txt", "new\n")
+	f.write(work+"/newdir/a.txt", "a\n")
+	f.write(work+"/newdir/sub/b.txt", "b\n")
+	f.write(work+"/debug.log", "ignored\n")
+	f.write(work+"/build/out.bin", "ignored\n")
+	f.write(work+"/logs/only.log", "ignored dir content\n")
+	f.write(work+"/dir5/extra.txt", "untracked in tracked dir\n")
+	if err := os.MkdirAll(filepath.Join(repo, "empty"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	assertParity(t, repo, "untracked and ignored files")
+
+	f.git(work, "add", "new.txt", "dir1/file1.txt")
+	f.git(work, "mv", "dir6/file6.txt", "dir6/renamed.txt")
+	f.git(work, "add", "-N", "newdir/a.txt")
+	if err := os.Symlink("new.txt", filepath.Join(repo, "link")); err != nil {
+		t.Fatal(err)
+	}
+	f.git(work, "add", "link")
+	assertParity(t, repo, "staged changes")
+
+	f.git(work, "add", "-A")
+	f.git(work, "commit", "-q", "-m", "local work")
+	f.write(work+"/dir8/file8.txt", "stashed\n")
+	f.git(work, "stash", "-q")
+	assertParity(t, repo, "ahead with stash")
+
+	// Someone else pushes, then we fetch: ahead and behind
+	f.git("", "clone", "-q", "remote.git", "other")
+	f.write("other/dir7/file7.txt", "theirs\n")
+	f.git("other", "commit", "-q", "-am", "their work")
+	f.git("other", "push", "-q", "origin", "main")
+	f.git(work, "fetch", "-q")
+	assertParity(t, repo, "diverged")
+
+	f.write(work+"/dir7/file7.txt", "ours\n")
+	f.git(work, "commit", "-q", "-am", "our work")
+	if _, err := f.run(work, "merge", "-q", "origin/main"); err == nil {
+		t.Fatal("merge was expected to conflict")
+	}
+	assertParity(t, repo, "merge conflict")
+	f.git(work, "merge", "--abort")
+
+	f.git(work, "checkout", "-q", "-b", "feature")
+	f.write(work+"/feature.txt", "feature\n")
+	f.git(work, "add", "feature.txt")
+	f.git(work, "commit", "-q", "-m", "feature")
+	assertParity(t, repo, "branch without upstream")
+
+	// Local branches sharing unpushed commits, one of them through a merge
+	f.git(work, "checkout", "-q", "-b", "side", "main")
+	f.write(work+"/side.txt", "side\n")
+	f.git(work, "add", "side.txt")
+	f.git(work, "commit", "-q", "-m", "side")
+	f.git(work, "checkout", "-q", "feature")
+	f.git(work, "merge", "-q", "--no-edit", "side")
+	assertParity(t, repo, "merged local branches")
+
+	f.git(work, "checkout", "-q", "--detach", "HEAD~1")
+	assertParity(t, repo, "detached HEAD")
+
+	f.git(work, "checkout", "-q", "main")
+	f.git("", "init", "-q", "lib")
+	f.write("lib/lib.txt", "lib\n")
+	f.git("lib", "add", "-A")
+	f.git("lib", "commit", "-q", "-m", "lib")
+	f.git(work, "submodule", "add", "-q", "../lib", "lib")
+	f.git(work, "commit", "-q", "-m", "add lib")
+	assertParity(t, repo, "clean submodule")
+
+	f.write(work+"/lib/lib.txt", "changed\n")
+	f.write(work+"/lib/untracked.txt", "new\n")
+	assertParity(t, repo, "dirty submodule")
+}
+
+// TestNativeFallsBack checks that repos the native backend cannot read
+// are handed to git
+func TestNativeFallsBack(t *testing.T) {
+	f := newFixture(t)
+	work := f.cloneWithUpstream(3)
+	repo := filepath.Join(f.dir, work)
+	f.git(work, "update-index", "--split-index")
+
+	if _, err := nativeStatus(context.Background(), repo); err == nil {
+		t.Fatal("split index was read natively")
+	}
+	got, err := NativeProvider{}.Status(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("fallback: %v", err)
+	}
+	want, _ := ExecProvider{}.Status(context.Background(), repo)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fallback status differs\n got: %+v\nwant: %+v", got, want)
+	}
+}
+
+// BenchmarkStatus compares the backends on a small repo and on one with
+// a few hundred files, both with some local changes. Most of the native
+// time goes to the lstat and readdir calls git makes as well, so the gap
+// is mostly git's startup cost and narrows as repos grow.
+func BenchmarkStatus(b *testing.B) {
+	for _, files := range []int{20, 500} {
+		repo := benchmarkRepo(b, files)
+		for _, p := range []struct {
+			name     string
+			provider StatusProvider
+		}{
+			{BackendExec, ExecProvider{}},
+			{BackendNative, NativeProvider{}},
+		} {
+			b.Run(fmt.Sprintf("%s/%d-files", p.name, files), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := p.provider.Status(context.Background(), repo); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// benchmarkRepo returns a packed clone with a modified and an untracked
+// file whose index stat data is up to date
+func benchmarkRepo(b *testing.B, files int) string {
+	f := newFixture(b)
+	work := f.cloneWithUpstream(files)
+	repo := filepath.Join(f.dir, work)
+	f.git(work, "gc", "-q")
+
+	// A fresh checkout is racily clean: files share the index's mtime and
+	// both backends would hash every one. Age them like a repo that has
+	// sat for a while and let git record the new stat data.
+	old := time.Now().Add(-time.Hour)
+	err := filepath.Walk(repo, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		return os.Chtimes(path, old, old)
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	f.git(work, "update-index", "-q", "--refresh")
+
+	f.write(work+"/dir1/file1.txt", "changed\n")
+	f.write(work+"/untracked.txt", "new\n")
+	return repo
+}
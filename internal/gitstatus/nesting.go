@@ -0,0 +1,52 @@
+package gitstatus
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Bharath-code/git-scope/internal/model"
+)
+
+// Nesting tells how a repo found inside the working tree of parent
+// relates to it: a submodule registered in the parent's .gitmodules, a
+// vendored checkout the parent's ignore rules exclude, or an unrelated
+// repo that just happens to live there. Both paths are working trees.
+func Nesting(parent string, parentLayout Layout, child string) model.Nesting {
+	rel, err := filepath.Rel(parent, child)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	rel = filepath.ToSlash(rel)
+
+	if data, err := os.ReadFile(filepath.Join(parent, ".gitmodules")); err == nil {
+		modules := make(gitConfig)
+		parseGitConfig(string(data), modules)
+		for key, values := range modules {
+			if !strings.HasPrefix(key, "submodule.") || !strings.HasSuffix(key, ".path") {
+				continue
+			}
+			for _, path := range values {
+				if strings.Trim(filepath.ToSlash(path), "/") == rel {
+					return model.NestingSubmodule
+				}
+			}
+		}
+	}
+
+	// As in git, nothing below an ignored directory can be re-included,
+	// so each directory on the way down is checked
+	ignores := baseIgnores(loadGitConfig(parentLayout.CommonDir), parentLayout.CommonDir)
+	dir := ""
+	for _, name := range strings.Split(rel, "/") {
+		ignores = ignores.withFile(filepath.Join(parent, filepath.FromSlash(dir), ".gitignore"), dir)
+		if dir != "" {
+			dir += "/"
+		}
+		dir += name
+		if ignores.ignored(dir, true) {
+			return model.NestingVendored
+		}
+	}
+	return model.NestingNested
+}
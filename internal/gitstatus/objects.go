@@ -0,0 +1,537 @@
+package gitstatus
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// errUnsupported marks repository features the native backend does not
+// read. NativeProvider falls back to running git when it sees it.
+var errUnsupported = errors.New("not supported by the native backend")
+
+// objectID is a SHA-1 object name
+type objectID [20]byte
+
+// parseObjectID decodes a 40 character hex object name
+func parseObjectID(s string) (objectID, bool) {
+	var id objectID
+	if len(s) != 2*len(id) {
+		return id, false
+	}
+	if _, err := hex.Decode(id[:], []byte(s)); err != nil {
+		return id, false
+	}
+	return id, true
+}
+
+func (id objectID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// objectType is the type of a git object as encoded in pack files
+type objectType byte
+
+const (
+	objCommit   objectType = 1
+	objTree     objectType = 2
+	objBlob     objectType = 3
+	objTag      objectType = 4
+	objOfsDelta objectType = 6
+	objRefDelta objectType = 7
+)
+
+// objectTypes maps the type names of loose object headers
+var objectTypes = map[string]objectType{
+	"commit": objCommit,
+	"tree":   objTree,
+	"blob":   objBlob,
+	"tag":    objTag,
+}
+
+// objectStore reads loose and packed objects of a repository
+type objectStore struct {
+	dirs        []string // The repo's object directory, then its alternates
+	packs       []*packFile
+	packsLoaded bool
+}
+
+// openObjectStore opens the object database of a common git directory,
+// including the object directories listed in objects/info/alternates
+func openObjectStore(commonDir string) *objectStore {
+	objects := filepath.Join(commonDir, "objects")
+	s := &objectStore{dirs: []string{objects}}
+
+	data, err := os.ReadFile(filepath.Join(objects, "info", "alternates"))
+	if err != nil {
+		return s
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(objects, line)
+		}
+		s.dirs = append(s.dirs, line)
+	}
+	return s
+}
+
+// close releases the open pack files
+func (s *objectStore) close() {
+	for _, p := range s.packs {
+		p.close()
+	}
+	s.packs = nil
+}
+
+// read returns the type and inflated content of an object. Deltas are
+// resolved, so the type is never objOfsDelta or objRefDelta. A missing
+// object is reported as errUnsupported since it is most likely held by a
+// promisor remote git would fetch it from.
+func (s *objectStore) read(id objectID) (objectType, []byte, error) {
+	name := id.String()
+	for _, dir := range s.dirs {
+		data, err := os.ReadFile(filepath.Join(dir, name[:2], name[2:]))
+		if err == nil {
+			return parseLooseObject(data)
+		}
+	}
+
+	if err := s.loadPacks(); err != nil {
+		return 0, nil, err
+	}
+	for _, p := range s.packs {
+		off, ok, err := p.find(id)
+		if err != nil {
+			return 0, nil, err
+		}
+		if ok {
+			return p.readObject(s, off)
+		}
+	}
+	return 0, nil, fmt.Errorf("object %s not found: %w", name, errUnsupported)
+}
+
+// loadPacks opens the index of every pack in the object directories
+func (s *objectStore) loadPacks() error {
+	if s.packsLoaded {
+		return nil
+	}
+	s.packsLoaded = true
+
+	for _, dir := range s.dirs {
+		idxFiles, _ := filepath.Glob(filepath.Join(dir, "pack", "*.idx"))
+		for _, idx := range idxFiles {
+			p, err := openPack(idx)
+			if err != nil {
+				return err
+			}
+			if p != nil {
+				s.packs = append(s.packs, p)
+			}
+		}
+	}
+	return nil
+}
+
+// parseLooseObject inflates a loose object and splits off its header
+func parseLooseObject(data []byte) (objectType, []byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return 0, nil, fmt.Errorf("inflate object: %w", err)
+	}
+	defer zr.Close()
+
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return 0, nil, fmt.Errorf("inflate object: %w", err)
+	}
+
+	header, content, ok := bytes.Cut(raw, []byte{0})
+	if !ok {
+		return 0, nil, errors.New("malformed object header")
+	}
+	typeName, _, _ := strings.Cut(string(header), " ")
+	typ, ok := objectTypes[typeName]
+	if !ok {
+		return 0, nil, fmt.Errorf("unknown object type %q", typeName)
+	}
+	return typ, content, nil
+}
+
+// packFile is a pack with a version 2 index. Lookups read the index
+// with ReadAt instead of loading it, since a scan usually needs only a
+// handful of objects from packs that can be hundreds of megabytes.
+type packFile struct {
+	idx    *os.File
+	pack   *os.File
+	fanout [256]uint32
+}
+
+// openPack opens a pack index and its pack. It returns nil if the pack
+// itself is missing, which happens briefly while git repacks.
+func openPack(idxPath string) (*packFile, error) {
+	pack, err := os.Open(strings.TrimSuffix(idxPath, ".idx") + ".pack")
+	if err != nil {
+		return nil, nil
+	}
+	idx, err := os.Open(idxPath)
+	if err != nil {
+		pack.Close()
+		return nil, nil
+	}
+	p := &packFile{idx: idx, pack: pack}
+
+	var header [8 + 256*4]byte
+	if _, err := idx.ReadAt(header[:], 0); err != nil {
+		p.close()
+		return nil, fmt.Errorf("read pack index %s: %w", idxPath, err)
+	}
+	if !bytes.Equal(header[:4], []byte{0xff, 't', 'O', 'c'}) || binary.BigEndian.Uint32(header[4:8]) != 2 {
+		p.close()
+		return nil, fmt.Errorf("pack index %s: %w", idxPath, errUnsupported)
+	}
+	for i := range p.fanout {
+		p.fanout[i] = binary.BigEndian.Uint32(header[8+4*i:])
+	}
+	return p, nil
+}
+
+func (p *packFile) close() {
+	p.idx.Close()
+	p.pack.Close()
+}
+
+// find looks an object up in the pack index and returns its pack offset
+func (p *packFile) find(id objectID) (int64, bool, error) {
+	count := int64(p.fanout[255])
+	lo := int64(0)
+	if id[0] > 0 {
+		lo = int64(p.fanout[id[0]-1])
+	}
+	hi := int64(p.fanout[id[0]])
+
+	const namesAt = 8 + 256*4
+	var name objectID
+	var searchErr error
+	i := lo + int64(sort.Search(int(hi-lo), func(i int) bool {
+		if _, err := p.idx.ReadAt(name[:], namesAt+(lo+int64(i))*20); err != nil {
+			searchErr = err
+			return true
+		}
+		return bytes.Compare(name[:], id[:]) >= 0
+	}))
+	if searchErr != nil {
+		return 0, false, fmt.Errorf("read pack index: %w", searchErr)
+	}
+	if i >= hi {
+		return 0, false, nil
+	}
+	if _, err := p.idx.ReadAt(name[:], namesAt+i*20); err != nil {
+		return 0, false, fmt.Errorf("read pack index: %w", err)
+	}
+	if name != id {
+		return 0, false, nil
+	}
+
+	// Offsets follow the names and their CRCs. Offsets of 2GiB and more
+	// are stored in a separate table of 64 bit values.
+	var buf [8]byte
+	offsetsAt := namesAt + count*24
+	if _, err := p.idx.ReadAt(buf[:4], offsetsAt+i*4); err != nil {
+		return 0, false, fmt.Errorf("read pack index: %w", err)
+	}
+	off := binary.BigEndian.Uint32(buf[:4])
+	if off&0x80000000 == 0 {
+		return int64(off), true, nil
+	}
+	largeAt := offsetsAt + count*4 + int64(off&0x7fffffff)*8
+	if _, err := p.idx.ReadAt(buf[:], largeAt); err != nil {
+		return 0, false, fmt.Errorf("read pack index: %w", err)
+	}
+	return int64(binary.BigEndian.Uint64(buf[:])), true, nil
+}
+
+// readObject reads the object at a pack offset, resolving deltas
+// against their base objects
+func (p *packFile) readObject(s *objectStore, off int64) (objectType, []byte, error) {
+	var header [32]byte
+	n, err := p.pack.ReadAt(header[:], off)
+	if err != nil && n == 0 {
+		return 0, nil, fmt.Errorf("read pack: %w", err)
+	}
+	buf := header[:n]
+
+	// Type and inflated size: 3 type bits, then a little-endian varint
+	c := buf[0]
+	typ := objectType(c >> 4 & 7)
+	size := int64(c & 0x0f)
+	shift := uint(4)
+	i := 1
+	for c&0x80 != 0 {
+		if i >= len(buf) {
+			return 0, nil, errors.New("malformed pack entry header")
+		}
+		c = buf[i]
+		i++
+		size |= int64(c&0x7f) << shift
+		shift += 7
+	}
+
+	var baseOff int64
+	var baseID objectID
+	switch typ {
+	case objOfsDelta:
+		// Distance back to the base, in git's offset varint encoding
+		if i >= len(buf) {
+			return 0, nil, errors.New("malformed pack delta offset")
+		}
+		c = buf[i]
+		i++
+		dist := int64(c & 0x7f)
+		for c&0x80 != 0 {
+			if i >= len(buf) {
+				return 0, nil, errors.New("malformed pack delta offset")
+			}
+			c = buf[i]
+			i++
+			dist = (dist+1)<<7 | int64(c&0x7f)
+		}
+		baseOff = off - dist
+	case objRefDelta:
+		if i+20 > len(buf) {
+			return 0, nil, errors.New("malformed pack delta base")
+		}
+		copy(baseID[:], buf[i:i+20])
+		i += 20
+	}
+
+	zr, err := zlib.NewReader(io.NewSectionReader(p.pack, off+int64(i), 1<<62))
+	if err != nil {
+		return 0, nil, fmt.Errorf("inflate pack entry: %w", err)
+	}
+	defer zr.Close()
+	data := make([]byte, size)
+	if _, err := io.ReadFull(zr, data); err != nil {
+		return 0, nil, fmt.Errorf("inflate pack entry: %w", err)
+	}
+
+	var baseType objectType
+	var base []byte
+	switch typ {
+	case objOfsDelta:
+		baseType, base, err = p.readObject(s, baseOff)
+	case objRefDelta:
+		baseType, base, err = s.read(baseID)
+	default:
+		return typ, data, nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	out, err := applyDelta(base, data)
+	if err != nil {
+		return 0, nil, err
+	}
+	return baseType, out, nil
+}
+
+// applyDelta rebuilds an object from its base and a pack delta
+func applyDelta(base, delta []byte) ([]byte, error) {
+	errMalformed := errors.New("malformed pack delta")
+
+	srcSize, n := deltaHeaderSize(delta)
+	if n == 0 || srcSize != len(base) {
+		return nil, errMalformed
+	}
+	delta = delta[n:]
+	dstSize, n := deltaHeaderSize(delta)
+	if n == 0 {
+		return nil, errMalformed
+	}
+	delta = delta[n:]
+
+	out := make([]byte, 0, dstSize)
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+
+		switch {
+		case op&0x80 != 0:
+			// Copy from base: up to 4 offset and 3 size bytes, present
+			// according to the low bits of op
+			var off, size int
+			for i := 0; i < 7; i++ {
+				if op&(1<<i) == 0 {
+					continue
+				}
+				if len(delta) == 0 {
+					return nil, errMalformed
+				}
+				if i < 4 {
+					off |= int(delta[0]) << (8 * i)
+				} else {
+					size |= int(delta[0]) << (8 * (i - 4))
+				}
+				delta = delta[1:]
+			}
+			if size == 0 {
+				size = 0x10000
+			}
+			if off+size > len(base) {
+				return nil, errMalformed
+			}
+			out = append(out, base[off:off+size]...)
+		case op != 0:
+			// Insert the next op bytes literally
+			if int(op) > len(delta) {
+				return nil, errMalformed
+			}
+			out = append(out, delta[:op]...)
+			delta = delta[op:]
+		default:
+			return nil, errMalformed
+		}
+	}
+
+	if len(out) != dstSize {
+		return nil, errMalformed
+	}
+	return out, nil
+}
+
+// deltaHeaderSize decodes a size varint at the start of a delta. It
+// returns n = 0 if the data ends early.
+func deltaHeaderSize(data []byte) (size int, n int) {
+	shift := uint(0)
+	for i, c := range data {
+		size |= int(c&0x7f) << shift
+		shift += 7
+		if c&0x80 == 0 {
+			return size, i + 1
+		}
+	}
+	return 0, 0
+}
+
+// commit holds the fields of a commit object the native backend uses
+type commit struct {
+	tree    objectID
+	parents []objectID
+	time    int64 // Committer timestamp
+}
+
+// readCommit reads a commit, peeling annotated tags that point to one
+func (s *objectStore) readCommit(id objectID) (commit, error) {
+	for depth := 0; depth < 10; depth++ {
+		typ, data, err := s.read(id)
+		if err != nil {
+			return commit{}, err
+		}
+		switch typ {
+		case objCommit:
+			return parseCommit(data)
+		case objTag:
+			target, ok := parseTagTarget(data)
+			if !ok {
+				return commit{}, fmt.Errorf("malformed tag %s", id)
+			}
+			id = target
+		default:
+			return commit{}, fmt.Errorf("object %s is not a commit", id)
+		}
+	}
+	return commit{}, fmt.Errorf("tag chain at %s too deep", id)
+}
+
+// parseCommit parses the header of a commit object
+func parseCommit(data []byte) (commit, error) {
+	var c commit
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			// End of the header, the message follows
+			break
+		}
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "tree":
+			id, ok := parseObjectID(value)
+			if !ok {
+				return c, errors.New("malformed commit tree")
+			}
+			c.tree = id
+		case "parent":
+			id, ok := parseObjectID(value)
+			if !ok {
+				return c, errors.New("malformed commit parent")
+			}
+			c.parents = append(c.parents, id)
+		case "committer":
+			// Name <email> <timestamp> <tz>
+			fields := strings.Fields(value)
+			if len(fields) >= 2 {
+				c.time, _ = strconv.ParseInt(fields[len(fields)-2], 10, 64)
+			}
+		}
+	}
+	return c, nil
+}
+
+// parseTagTarget returns the object an annotated tag points to
+func parseTagTarget(data []byte) (objectID, bool) {
+	line, _, _ := strings.Cut(string(data), "\n")
+	value, ok := strings.CutPrefix(line, "object ")
+	if !ok {
+		return objectID{}, false
+	}
+	return parseObjectID(value)
+}
+
+// treeEntry is a single entry of a tree object
+type treeEntry struct {
+	mode uint32
+	name string
+	id   objectID
+}
+
+// readTree reads and parses a tree object
+func (s *objectStore) readTree(id objectID) ([]treeEntry, error) {
+	typ, data, err := s.read(id)
+	if err != nil {
+		return nil, err
+	}
+	if typ != objTree {
+		return nil, fmt.Errorf("object %s is not a tree", id)
+	}
+
+	var entries []treeEntry
+	for len(data) > 0 {
+		// <octal mode> <name>\0<20 byte id>
+		sp := bytes.IndexByte(data, ' ')
+		nul := bytes.IndexByte(data, 0)
+		if sp < 0 || nul < sp || nul+21 > len(data) {
+			return nil, fmt.Errorf("malformed tree %s", id)
+		}
+		mode, err := strconv.ParseUint(string(data[:sp]), 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("malformed tree %s", id)
+		}
+		e := treeEntry{mode: uint32(mode), name: string(data[sp+1 : nul])}
+		copy(e.id[:], data[nul+1:nul+21])
+		entries = append(entries, e)
+		data = data[nul+21:]
+	}
+	return entries, nil
+}
@@ -0,0 +1,37 @@
+package gitstatus
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/Bharath-code/git-scope/internal/model"
+)
+
+// operationMarkers maps the state files git leaves in the git directory
+// to the operation they belong to, in the order they are checked.
+// A rebase that stops on a conflicting merge also writes MERGE_HEAD
+// style files, so rebase is checked first. git am keeps its state in
+// rebase-apply too, marked by an applying file.
+var operationMarkers = []struct {
+	name string
+	op   model.Operation
+}{
+	{"rebase-merge", model.OpRebase},
+	{filepath.Join("rebase-apply", "applying"), model.OpAm},
+	{"rebase-apply", model.OpRebase},
+	{"MERGE_HEAD", model.OpMerge},
+	{"CHERRY_PICK_HEAD", model.OpCherryPick},
+	{"REVERT_HEAD", model.OpRevert},
+	{"BISECT_LOG", model.OpBisect},
+}
+
+// detectOperation reports which multi-step operation, if any, is in
+// progress for the worktree whose git directory is gitDir
+func detectOperation(gitDir string) model.Operation {
+	for _, m := range operationMarkers {
+		if _, err := os.Stat(filepath.Join(gitDir, m.name)); err == nil {
+			return m.op
+		}
+	}
+	return ""
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package gitstatus
+
+// ownedByOther always reports false where file ownership is not a uid;
+// git's own ownership checks there are left to the exec backend
+func ownedByOther(path string) bool {
+	return false
+}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package gitstatus
+
+import (
+	"os"
+	"syscall"
+)
+
+// ownedByOther reports whether a path belongs to a user other than the
+// one running git-scope, which git refuses without safe.directory
+func ownedByOther(path string) bool {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(st.Uid) != os.Geteuid()
+}
@@ -0,0 +1,235 @@
+package gitstatus
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Bharath-code/git-scope/internal/model"
+)
+
+func TestParseSubmoduleLine(t *testing.T) {
+	const sha = "3f786850e387550fdab836ed7e6dc881de23001b"
+	tests := []struct {
+		line string
+		want model.Submodule
+		ok   bool
+	}{
+		{" " + sha + " lib/core (v1.2.0)", model.Submodule{Commit: sha, Path: "lib/core"}, true},
+		{"-" + sha + " lib/uninit", model.Submodule{Commit: sha, Path: "lib/uninit", Uninitialized: true}, true},
+		{"+" + sha + " lib/moved (v1.2.0-3-g3f78685)", model.Submodule{Commit: sha, Path: "lib/moved", OutOfSync: true}, true},
+		{"U" + sha + " lib/conflict", model.Submodule{Commit: sha, Path: "lib/conflict", OutOfSync: true}, true},
+		{" " + sha + " path with (parens) (heads/main)", model.Submodule{Commit: sha, Path: "path with (parens)"}, true},
+		{"", model.Submodule{}, false},
+		{"-" + sha, model.Submodule{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseSubmoduleLine(tt.line)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSubmoduleLine(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseSubmoduleField(t *testing.T) {
+	const ids = "160000 160000 160000 1111111111111111111111111111111111111111 2222222222222222222222222222222222222222"
+	tests := []struct {
+		line  string
+		path  string
+		flags submoduleFlags
+		ok    bool
+	}{
+		{"1 .M SCM. " + ids + " lib/core", "lib/core", submoduleFlags{commitChanged: true, modified: true}, true},
+		{"1 .M S..U " + ids + " lib/core", "lib/core", submoduleFlags{untracked: true}, true},
+		{"2 R. S... " + ids + " R100 lib/new\tlib/old", "lib/new", submoduleFlags{}, true},
+		{"1 .M N... 100644 100644 100644 " + ids[28:] + " file.txt", "", submoduleFlags{}, false},
+		{"? untracked.txt", "", submoduleFlags{}, false},
+	}
+	for _, tt := range tests {
+		path, flags, ok := parseSubmoduleField(tt.line)
+		if path != tt.path || flags != tt.flags || ok != tt.ok {
+			t.Errorf("parseSubmoduleField(%q) = %q, %+v, %v; want %q, %+v, %v", tt.line, path, flags, ok, tt.path, tt.flags, tt.ok)
+		}
+	}
+}
+
+func TestApplyBranchHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		want    model.RepoStatus
+	}{
+		{
+			name:    "tracking",
+			headers: []string{"# branch.head main", "# branch.upstream origin/main", "# branch.ab +2 -1"},
+			want:    model.RepoStatus{Branch: "main", Upstream: "origin/main", Ahead: 2, Behind: 1},
+		},
+		{
+			// git leaves out branch.ab when the upstream branch is gone
+			name:    "gone",
+			headers: []string{"# branch.head feature", "# branch.upstream origin/feature"},
+			want:    model.RepoStatus{Branch: "feature", Upstream: "origin/feature", UpstreamGone: true},
+		},
+		{
+			name:    "no upstream",
+			headers: []string{"# branch.head local"},
+			want:    model.RepoStatus{Branch: "local", NoUpstream: true},
+		},
+		{
+			name:    "detached",
+			headers: []string{"# branch.head (detached)"},
+			want:    model.RepoStatus{Branch: "(detached)"},
+		},
+	}
+	for _, tt := range tests {
+		var got model.RepoStatus
+		for _, h := range tt.headers {
+			applyBranchHeader(&got, h)
+		}
+		applyUpstreamState(&got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReadStashes(t *testing.T) {
+	const zero = "0000000000000000000000000000000000000000"
+	const a = "1111111111111111111111111111111111111111"
+	const b = "2222222222222222222222222222222222222222"
+	reflog := zero + " " + a + " Test <test@example.com> 1700000000 +0100\tWIP on main: first\n" +
+		"garbage line\n" +
+		a + " " + b + " Test User <test@example.com> 1700000600 -0500\tOn main: second\n"
+
+	common := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(common, "logs", "refs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(common, "logs", "refs", "stash"), []byte(reflog), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readStashes(common)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The reflog is oldest first, stash@{0} is the newest
+	want := []model.Stash{
+		{Ref: "stash@{0}", Message: "On main: second", Time: time.Unix(1700000600, 0)},
+		{Ref: "stash@{1}", Message: "WIP on main: first", Time: time.Unix(1700000000, 0)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+
+	if got, err := readStashes(t.TempDir()); got != nil || err != nil {
+		t.Errorf("without a stash reflog: %+v, %v", got, err)
+	}
+}
+
+func TestResolveLayout(t *testing.T) {
+	root := t.TempDir()
+	mkdir := func(path string) string {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(full, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		return full
+	}
+	write := func(path, content string) {
+		if err := os.WriteFile(filepath.Join(root, path), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A plain repo, a linked worktree of it and an absorbed submodule
+	mainGit := mkdir("main/.git")
+	wtGit := mkdir("main/.git/worktrees/wt")
+	write("main/.git/worktrees/wt/commondir", "../..\n")
+	mkdir("wt")
+	write("wt/.git", "gitdir: "+wtGit+"\n")
+	subGit := mkdir("main/.git/modules/lib")
+	mkdir("main/lib")
+	write("main/lib/.git", "gitdir: ../.git/modules/lib\n")
+	mkdir("broken")
+	write("broken/.git", "gitdir: ../nowhere\n")
+
+	tests := []struct {
+		repo string
+		want Layout
+	}{
+		{"main", Layout{GitDir: mainGit, CommonDir: mainGit, Kind: model.KindRepo}},
+		{"wt", Layout{GitDir: wtGit, CommonDir: mainGit, Kind: model.KindWorktree, MainRepo: filepath.Join(root, "main")}},
+		{"main/lib", Layout{GitDir: subGit, CommonDir: subGit, Kind: model.KindSubmodule}},
+	}
+	for _, tt := range tests {
+		got, err := ResolveLayout(filepath.Join(root, tt.repo))
+		if err != nil {
+			t.Errorf("%s: %v", tt.repo, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.repo, got, tt.want)
+		}
+	}
+
+	if _, err := ResolveLayout(filepath.Join(root, "broken")); err == nil {
+		t.Error("broken: a gitfile pointing nowhere resolved")
+	}
+}
+
+func TestDetectOperation(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  model.Operation
+	}{
+		{"none", nil, ""},
+		{"rebase --apply", []string{"rebase-apply/rebasing"}, model.OpRebase},
+		{"am", []string{"rebase-apply/applying"}, model.OpAm},
+		{"rebase stopped on a conflict", []string{"rebase-merge/done", "MERGE_HEAD"}, model.OpRebase},
+		{"merge", []string{"MERGE_HEAD"}, model.OpMerge},
+		{"bisect", []string{"BISECT_LOG"}, model.OpBisect},
+	}
+	for _, tt := range tests {
+		gitDir := t.TempDir()
+		for _, f := range tt.files {
+			path := filepath.Join(gitDir, f)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, nil, 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := detectOperation(gitDir); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCountLocalOnly(t *testing.T) {
+	// c3 merges c2 and b1, which both build on c1. p is pushed.
+	parents := map[string][]string{
+		"c1": {"p"},
+		"c2": {"c1"},
+		"b1": {"c1"},
+		"c3": {"c2", "b1"},
+	}
+	tests := []struct {
+		tip  string
+		want int
+	}{
+		{"c3", 4},
+		{"c2", 2},
+		{"b1", 2},
+		{"p", 0},
+	}
+	for _, tt := range tests {
+		if got := countLocalOnly(tt.tip, parents); got != tt.want {
+			t.Errorf("countLocalOnly(%s) = %d, want %d", tt.tip, got, tt.want)
+		}
+	}
+}
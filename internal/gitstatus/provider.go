@@ -0,0 +1,60 @@
+package gitstatus
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Bharath-code/git-scope/internal/model"
+)
+
+// StatusProvider reads the status of a single repository
+type StatusProvider interface {
+	Status(ctx context.Context, repoPath string) (model.RepoStatus, error)
+}
+
+// Backend names, as set with git.backend in the config
+const (
+	BackendExec   = "exec"
+	BackendNative = "native"
+)
+
+// Backends lists the valid backend names
+var Backends = []string{BackendExec, BackendNative}
+
+// ProviderFor returns the provider of a backend. An empty or unknown
+// name selects the exec backend.
+func ProviderFor(backend string) StatusProvider {
+	if backend == BackendNative {
+		return NativeProvider{}
+	}
+	return ExecProvider{}
+}
+
+// ExecProvider reads status by running git, see Status
+type ExecProvider struct{}
+
+// Status implements StatusProvider
+func (ExecProvider) Status(ctx context.Context, repoPath string) (model.RepoStatus, error) {
+	return Status(ctx, repoPath)
+}
+
+// NativeProvider reads status straight from the repository files (HEAD,
+// refs, packed-refs, config, the index and the object database) without
+// starting a git process. It never runs hooks or filters, so of the
+// ExecOptions only IgnoreUserConfig applies to it.
+//
+// Repos it cannot read faithfully are handed to ExecProvider: repos owned
+// by another user, SHA-256 or reftable repos, split and sparse indexes, objects missing from a
+// partial clone, and modified files whose content git might convert.
+// Its counts match `git status`, except that staged renames are only
+// paired when the file content is unchanged.
+type NativeProvider struct{}
+
+// Status implements StatusProvider
+func (NativeProvider) Status(ctx context.Context, repoPath string) (model.RepoStatus, error) {
+	status, err := nativeStatus(ctx, repoPath)
+	if errors.Is(err, errUnsupported) {
+		return ExecProvider{}.Status(ctx, repoPath)
+	}
+	return status, err
+}
@@ -0,0 +1,197 @@
+package gitstatus
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// refStore resolves refs from the loose ref files and packed-refs of a
+// repository using the files backend
+type refStore struct {
+	gitDir    string // Per-worktree git directory, holds HEAD
+	commonDir string // Shared git directory, holds refs and packed-refs
+	packed    map[string]objectID
+}
+
+// perWorktreeRefs are the ref hierarchies git keeps in the per-worktree
+// git directory instead of the common one
+var perWorktreeRefs = []string{"refs/bisect/", "refs/worktree/", "refs/rewritten/"}
+
+// refPath returns the loose file of a ref
+func (r *refStore) refPath(name string) string {
+	dir := r.commonDir
+	if name == "HEAD" {
+		dir = r.gitDir
+	}
+	for _, prefix := range perWorktreeRefs {
+		if strings.HasPrefix(name, prefix) {
+			dir = r.gitDir
+		}
+	}
+	return filepath.Join(dir, filepath.FromSlash(name))
+}
+
+// loadPacked reads packed-refs once. Peeled lines (^<id>) are skipped.
+func (r *refStore) loadPacked() error {
+	if r.packed != nil {
+		return nil
+	}
+	r.packed = make(map[string]objectID)
+
+	data, err := os.ReadFile(filepath.Join(r.commonDir, "packed-refs"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read packed-refs: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+		hexID, name, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		if id, ok := parseObjectID(hexID); ok {
+			r.packed[name] = id
+		}
+	}
+	return nil
+}
+
+// readRef reads one level of a ref: either the object it points to or,
+// for a symbolic ref, the name of its target
+func (r *refStore) readRef(name string) (target string, id objectID, ok bool, err error) {
+	data, err := os.ReadFile(r.refPath(name))
+	if err == nil {
+		content := strings.TrimSpace(string(data))
+		if target, isSym := strings.CutPrefix(content, "ref: "); isSym {
+			return target, id, true, nil
+		}
+		id, ok := parseObjectID(content)
+		if !ok {
+			return "", id, false, fmt.Errorf("malformed ref %s", name)
+		}
+		return "", id, true, nil
+	}
+	// Anything but a permission problem (missing file, a directory of
+	// refs in its place) means the ref is not stored loose
+	if errors.Is(err, fs.ErrPermission) {
+		return "", id, false, fmt.Errorf("read ref %s: %w", name, err)
+	}
+
+	if err := r.loadPacked(); err != nil {
+		return "", id, false, err
+	}
+	id, ok = r.packed[name]
+	return "", id, ok, nil
+}
+
+// resolve follows symbolic refs and returns the object a ref points to.
+// ok is false if the ref, or the branch a symbolic ref names, does not exist.
+func (r *refStore) resolve(name string) (objectID, bool, error) {
+	for depth := 0; depth < 5; depth++ {
+		target, id, ok, err := r.readRef(name)
+		if err != nil || !ok {
+			return id, false, err
+		}
+		if target == "" {
+			return id, true, nil
+		}
+		name = target
+	}
+	return objectID{}, false, fmt.Errorf("symbolic ref %s nested too deep", name)
+}
+
+// head returns the branch checked out in the worktree and the commit HEAD
+// points to. branch is empty for a detached HEAD; ok is false for a branch
+// without commits yet.
+func (r *refStore) head() (branch string, id objectID, ok bool, err error) {
+	target, id, ok, err := r.readRef("HEAD")
+	if err != nil {
+		return "", id, false, err
+	}
+	if !ok {
+		return "", id, false, fmt.Errorf("missing HEAD")
+	}
+	if target == "" {
+		return "", id, true, nil
+	}
+
+	branch = strings.TrimPrefix(target, "refs/heads/")
+	id, ok, err = r.resolve(target)
+	return branch, id, ok, err
+}
+
+// list returns every ref below prefix (e.g. "refs/remotes/") with the
+// object it resolves to. Loose refs take precedence over packed ones.
+func (r *refStore) list(prefix string) (map[string]objectID, error) {
+	if err := r.loadPacked(); err != nil {
+		return nil, err
+	}
+
+	refs := make(map[string]objectID)
+	for name, id := range r.packed {
+		if strings.HasPrefix(name, prefix) {
+			refs[name] = id
+		}
+	}
+
+	root := filepath.Join(r.commonDir, filepath.FromSlash(prefix))
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasSuffix(path, ".lock") {
+			return nil
+		}
+		rel, err := filepath.Rel(r.commonDir, path)
+		if err != nil {
+			return nil
+		}
+		name := filepath.ToSlash(rel)
+		id, ok, err := r.resolve(name)
+		if err != nil {
+			return err
+		}
+		if ok {
+			refs[name] = id
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list refs %s: %w", prefix, err)
+	}
+	return refs, nil
+}
+
+// readShallow returns the commits whose parents were cut off by a
+// shallow clone
+func readShallow(commonDir string) (map[objectID]bool, error) {
+	data, err := os.ReadFile(filepath.Join(commonDir, "shallow"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	shallow := make(map[objectID]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if id, ok := parseObjectID(strings.TrimSpace(scanner.Text())); ok {
+			shallow[id] = true
+		}
+	}
+	return shallow, nil
+}
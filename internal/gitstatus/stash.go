@@ -0,0 +1,84 @@
+package gitstatus
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Bharath-code/git-scope/internal/model"
+)
+
+// Stashes lists the stash entries of a repository, newest first.
+// It reads the refs/stash reflog directly instead of spawning
+// `git stash list`; a repository without stashes returns an empty list.
+func Stashes(repoPath string) ([]model.Stash, error) {
+	layout, err := ResolveLayout(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return readStashes(layout.CommonDir)
+}
+
+// readStashes parses the refs/stash reflog of a common git directory.
+// Stashes are shared between all worktrees of a repository.
+func readStashes(commonDir string) ([]model.Stash, error) {
+	f, err := os.Open(filepath.Join(commonDir, "logs", "refs", "stash"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var stashes []model.Stash
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if stash, ok := parseStashReflogLine(scanner.Text()); ok {
+			stashes = append(stashes, stash)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read stash reflog: %w", err)
+	}
+
+	// The reflog is oldest first; stash@{0} is the newest entry
+	for i, j := 0, len(stashes)-1; i < j; i, j = i+1, j-1 {
+		stashes[i], stashes[j] = stashes[j], stashes[i]
+	}
+	for i := range stashes {
+		stashes[i].Ref = fmt.Sprintf("stash@{%d}", i)
+	}
+
+	return stashes, nil
+}
+
+// parseStashReflogLine parses a reflog line of the form
+// `<old> <new> <name> <<email>> <timestamp> <tz>\t<message>`.
+// It returns ok = false if the line cannot be parsed.
+func parseStashReflogLine(line string) (model.Stash, bool) {
+	header, message, ok := strings.Cut(line, "\t")
+	if !ok {
+		return model.Stash{}, false
+	}
+
+	parts := strings.Fields(header)
+	// Need at least old, new, timestamp and tz
+	if len(parts) < 4 {
+		return model.Stash{}, false
+	}
+
+	sec, err := strconv.ParseInt(parts[len(parts)-2], 10, 64)
+	if err != nil {
+		return model.Stash{}, false
+	}
+
+	return model.Stash{
+		Message: message,
+		Time:    time.Unix(sec, 0),
+	}, true
+}
@@ -0,0 +1,133 @@
+package gitstatus
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Bharath-code/git-scope/internal/model"
+)
+
+// submoduleFlags holds the porcelain v2 `S<c><m><u>` field of a submodule
+// entry, describing what changed inside the submodule
+type submoduleFlags struct {
+	commitChanged bool
+	modified      bool
+	untracked     bool
+}
+
+// parseSubmoduleField extracts the path and submodule state from a
+// porcelain v2 changed (`1`) or renamed (`2`) record. It returns ok = false
+// if the record does not describe a submodule.
+func parseSubmoduleField(line string) (path string, flags submoduleFlags, ok bool) {
+	var parts []string
+	switch {
+	case strings.HasPrefix(line, "1 "):
+		// 1 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <path>
+		parts = strings.SplitN(line, " ", 9)
+		if len(parts) < 9 {
+			return "", flags, false
+		}
+		path = parts[8]
+	case strings.HasPrefix(line, "2 "):
+		// 2 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <X><score> <path><tab><origPath>
+		parts = strings.SplitN(line, " ", 10)
+		if len(parts) < 10 {
+			return "", flags, false
+		}
+		path, _, _ = strings.Cut(parts[9], "\t")
+	default:
+		return "", flags, false
+	}
+
+	sub := parts[2]
+	if len(sub) != 4 || sub[0] != 'S' {
+		return "", flags, false
+	}
+
+	flags = submoduleFlags{
+		commitChanged: sub[1] == 'C',
+		modified:      sub[2] == 'M',
+		untracked:     sub[3] == 'U',
+	}
+	return path, flags, true
+}
+
+// submodules lists the submodules of a repository with their state.
+// The flags map carries what `git status` already reported per submodule
+// path; uninitialized and out-of-sync submodules come from
+// `git submodule status`, which reports them even when status ignores them.
+func submodules(ctx context.Context, repoPath string, flags map[string]submoduleFlags) ([]model.Submodule, error) {
+	// Cheap check before spawning git: no .gitmodules, no submodules
+	if _, err := os.Stat(filepath.Join(repoPath, ".gitmodules")); err != nil {
+		return nil, nil
+	}
+
+	out, err := Git(ctx, repoPath, "submodule", "status")
+	if err != nil {
+		return nil, fmt.Errorf("git submodule status: %w", err)
+	}
+
+	var subs []model.Submodule
+	for _, line := range strings.Split(string(out), "\n") {
+		sub, ok := parseSubmoduleLine(line)
+		if !ok {
+			continue
+		}
+
+		if f, found := flags[sub.Path]; found {
+			sub.OutOfSync = sub.OutOfSync || f.commitChanged
+			sub.Dirty = f.modified || f.untracked
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, nil
+}
+
+// parseSubmoduleLine parses a `git submodule status` line of the form
+// `<state><sha> <path> (<describe>)`, where state is ' ', '-', '+' or 'U'.
+// It returns ok = false if the line cannot be parsed.
+func parseSubmoduleLine(line string) (model.Submodule, bool) {
+	if len(line) < 2 {
+		return model.Submodule{}, false
+	}
+
+	state := line[0]
+	sha, rest, ok := strings.Cut(line[1:], " ")
+	if !ok || sha == "" || rest == "" {
+		return model.Submodule{}, false
+	}
+
+	// Strip the trailing " (<describe>)" that initialized submodules carry
+	path := rest
+	if i := strings.LastIndex(rest, " ("); i >= 0 && strings.HasSuffix(rest, ")") {
+		path = rest[:i]
+	}
+
+	sub := model.Submodule{
+		Commit: sha,
+		Path:   path,
+	}
+	switch state {
+	case '-':
+		sub.Uninitialized = true
+	case '+', 'U':
+		sub.OutOfSync = true
+	}
+
+	return sub, true
+}
+
+// submodulesNeedAttention reports whether any submodule is checked out at
+// the wrong commit or has local changes
+func submodulesNeedAttention(subs []model.Submodule) bool {
+	for _, s := range subs {
+		if s.OutOfSync || s.Dirty {
+			return true
+		}
+	}
+	return false
+}
@@ -2,22 +2,122 @@ package model
 
 import "time"
 
+// RepoKind describes how a repository's git directory is laid out on disk
+type RepoKind string
+
+const (
+	// KindRepo is a regular working tree with its own .git directory
+	KindRepo RepoKind = "repo"
+	// KindWorktree is a linked worktree created with `git worktree add`
+	KindWorktree RepoKind = "worktree"
+	// KindSubmodule is a submodule checkout whose git dir lives in the superproject
+	KindSubmodule RepoKind = "submodule"
+	// KindBare is a bare repository without a working tree, such as a mirror
+	KindBare RepoKind = "bare"
+)
+
+// Nesting describes how a repo inside another repo's working tree
+// relates to that parent repo
+type Nesting string
+
+const (
+	// NestingNested is an independent repo the parent doesn't know about
+	NestingNested Nesting = "nested"
+	// NestingSubmodule is registered in the parent's .gitmodules
+	NestingSubmodule Nesting = "submodule"
+	// NestingVendored is excluded by the parent's ignore rules, like a
+	// dependency checked out into the parent's tree
+	NestingVendored Nesting = "vendored"
+)
+
+// Operation is a multi-step git operation left in progress in a worktree
+type Operation string
+
+const (
+	OpMerge      Operation = "merge"
+	OpRebase     Operation = "rebase"
+	OpAm         Operation = "am"
+	OpCherryPick Operation = "cherry-pick"
+	OpRevert     Operation = "revert"
+	OpBisect     Operation = "bisect"
+)
+
+// Submodule describes the state of a submodule relative to its superproject
+type Submodule struct {
+	Path          string `json:"path"`
+	Commit        string `json:"commit,omitempty"`        // Checked out commit (recorded commit if uninitialized)
+	Uninitialized bool   `json:"uninitialized,omitempty"` // Not cloned / checked out yet
+	OutOfSync     bool   `json:"out_of_sync,omitempty"`   // Checked out at a commit other than the recorded one
+	Dirty         bool   `json:"dirty,omitempty"`         // Modified or untracked content inside the submodule
+}
+
+// Stash is a single entry of a repository's stash list
+type Stash struct {
+	Ref     string    `json:"ref"` // e.g. stash@{0}
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// UnpushedBranch is a local branch with commits that are not reachable
+// from any remote-tracking branch
+type UnpushedBranch struct {
+	Name    string `json:"name"`
+	Commits int    `json:"commits"`
+}
+
+// BranchTip is a branch of a bare repository and the time of its last commit
+type BranchTip struct {
+	Name       string    `json:"name"`
+	LastCommit time.Time `json:"last_commit"`
+}
+
+// BareInfo describes a bare repository, which has no working tree to
+// report changes for
+type BareInfo struct {
+	Mirror    bool        `json:"mirror,omitempty"` // Created with clone --mirror
+	Branches  []BranchTip `json:"branches"`         // Most recent commit first
+	SizeBytes int64       `json:"size_bytes"`       // Size of the object database
+	LastFetch time.Time   `json:"last_fetch"`       // Zero if never fetched
+}
+
 // RepoStatus contains the git status information for a repository
 type RepoStatus struct {
-	Branch     string    `json:"branch"`
-	Ahead      int       `json:"ahead"`
-	Behind     int       `json:"behind"`
-	Staged     int       `json:"staged"`
-	Unstaged   int       `json:"unstaged"`
-	Untracked  int       `json:"untracked"`
-	LastCommit time.Time `json:"last_commit"`
-	IsDirty    bool      `json:"is_dirty"`
-	ScanError  string    `json:"scan_error,omitempty"`
+	Branch           string           `json:"branch"`
+	Upstream         string           `json:"upstream,omitempty"`
+	NoUpstream       bool             `json:"no_upstream,omitempty"`   // Branch has no tracking branch configured
+	UpstreamGone     bool             `json:"upstream_gone,omitempty"` // Tracking branch was deleted on the remote
+	Ahead            int              `json:"ahead"`
+	Behind           int              `json:"behind"`
+	Unpublished      int              `json:"unpublished"` // Commits on HEAD not present on any remote (no or gone upstream)
+	Staged           int              `json:"staged"`
+	Unstaged         int              `json:"unstaged"`
+	Untracked        int              `json:"untracked"`
+	Conflicts        int              `json:"conflicts"`
+	Operation        Operation        `json:"operation,omitempty"`
+	Stashes          int              `json:"stashes"`
+	LastCommit       time.Time        `json:"last_commit"`
+	IsDirty          bool             `json:"is_dirty"`
+	Submodules       []Submodule      `json:"submodules,omitempty"`
+	UnpushedBranches []UnpushedBranch `json:"unpushed_branches,omitempty"`
+	ScanError        string           `json:"scan_error,omitempty"`
+	TimedOut         bool             `json:"timed_out,omitempty"` // git did not finish within the per-repo timeout
+	Untrusted        bool             `json:"untrusted,omitempty"` // git refused the repo because another user owns it
+	Bare             *BareInfo        `json:"bare,omitempty"`      // Set instead of the worktree counts for bare repos
 }
 
 // Repo represents a git repository with its metadata and status
 type Repo struct {
-	Name   string     `json:"name"`
-	Path   string     `json:"path"`
-	Status RepoStatus `json:"status"`
+	Name     string     `json:"name"`
+	Path     string     `json:"path"`
+	Kind     RepoKind   `json:"kind"`
+	GitDir   string     `json:"git_dir,omitempty"`
+	MainRepo string     `json:"main_repo,omitempty"` // Main working tree of a linked worktree
+	Parent   string     `json:"parent,omitempty"`    // Repo whose working tree contains this one
+	Nesting  Nesting    `json:"nesting,omitempty"`   // How this repo relates to Parent
+	Aliases  []string   `json:"aliases,omitempty"`   // Other paths the scan reached it by, via symlinks
+	Status   RepoStatus `json:"status"`
+
+	// Fingerprint of the repo's git files when Status was collected, see
+	// gitstatus.Fingerprint. Kept in the cache, not in scan output.
+	Fingerprint string `json:"-"`
 }
@@ -0,0 +1,122 @@
+package scan
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Bharath-code/git-scope/internal/model"
+)
+
+// Filter selects repos by their state. All set conditions must hold for a
+// repo to match; the zero value matches every repo.
+type Filter struct {
+	Dirty      bool          // Uncommitted changes, conflicts or unpushed commits
+	Clean      bool          // Nothing to commit or push, and scanned without error
+	Ahead      bool          // Commits not pushed: ahead of upstream, not on any remote or on another unpushed branch
+	Behind     bool          // Upstream has commits not pulled yet
+	NoUpstream bool          // Branch without a tracking branch, or with a gone one
+	Branch     string        // Glob matched against the branch name
+	Name       string        // Glob matched against the repo name
+	Stale      time.Duration // Last commit older than this
+}
+
+// Validate checks the glob patterns of the filter
+func (f Filter) Validate() error {
+	for _, pattern := range []string{f.Branch, f.Name} {
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Match reports whether a repo satisfies every condition of the filter
+func (f Filter) Match(r model.Repo, now time.Time) bool {
+	s := r.Status
+
+	if f.Dirty && !s.IsDirty && !HasUnpublishedWork(s) {
+		return false
+	}
+	// A repo that could not be scanned is not known to be clean
+	if f.Clean && (s.IsDirty || HasUnpublishedWork(s) || s.ScanError != "") {
+		return false
+	}
+	if f.Ahead && !HasUnpublishedWork(s) {
+		return false
+	}
+	if f.Behind && s.Behind == 0 {
+		return false
+	}
+	if f.NoUpstream && !s.NoUpstream && !s.UpstreamGone {
+		return false
+	}
+	if f.Branch != "" && !globMatch(f.Branch, s.Branch) {
+		return false
+	}
+	if f.Name != "" && !globMatch(f.Name, r.Name) {
+		return false
+	}
+	if f.Stale > 0 && (s.LastCommit.IsZero() || now.Sub(s.LastCommit) < f.Stale) {
+		return false
+	}
+	return true
+}
+
+// HasUnpublishedWork reports whether a repo has commits that exist only
+// locally: ahead of its upstream, on a branch without a usable upstream,
+// or on any other local branch that was never pushed
+func HasUnpublishedWork(s model.RepoStatus) bool {
+	return s.Unpublished > 0 || s.Ahead > 0 || len(s.UnpushedBranches) > 0
+}
+
+// Apply returns the repos that match the filter
+func (f Filter) Apply(repos []model.Repo) []model.Repo {
+	now := time.Now()
+	matched := make([]model.Repo, 0, len(repos))
+	for _, r := range repos {
+		if f.Match(r, now) {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// globMatch matches a shell glob, treating malformed patterns as no match
+func globMatch(pattern, s string) bool {
+	ok, err := path.Match(pattern, s)
+	return err == nil && ok
+}
+
+// ParseAge parses an age such as "30d", "2w", "12h" or "90m".
+// Days and weeks are not supported by time.ParseDuration, so they are
+// handled here; anything else falls through to it.
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+
+	units := map[byte]time.Duration{
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+	}
+	if unit, ok := units[s[len(s)-1]]; ok {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * unit, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %q (use e.g. 30d, 2w, 12h)", s)
+	}
+	return d, nil
+}
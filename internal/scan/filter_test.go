@@ -0,0 +1,37 @@
+package scan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Bharath-code/git-scope/internal/model"
+)
+
+func TestFilterMatch(t *testing.T) {
+	unpushedSide := model.RepoStatus{UnpushedBranches: []model.UnpushedBranch{{Name: "side"}}}
+	timedOut := model.RepoStatus{ScanError: "git status timed out", TimedOut: true}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		status model.RepoStatus
+		want   bool
+	}{
+		{"ahead matches an unpushed side branch", Filter{Ahead: true}, unpushedSide, true},
+		{"ahead matches unpublished commits", Filter{Ahead: true}, model.RepoStatus{Unpublished: 1}, true},
+		{"ahead skips a pushed repo", Filter{Ahead: true}, model.RepoStatus{}, false},
+		{"dirty matches an unpushed side branch", Filter{Dirty: true}, unpushedSide, true},
+		{"clean skips an unpushed side branch", Filter{Clean: true}, unpushedSide, false},
+		{"clean skips a repo that failed to scan", Filter{Clean: true}, timedOut, false},
+		{"clean matches a clean repo", Filter{Clean: true}, model.RepoStatus{}, true},
+	}
+
+	now := time.Now()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(model.Repo{Status: tt.status}, now); got != tt.want {
+				t.Errorf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
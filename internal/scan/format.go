@@ -0,0 +1,344 @@
+package scan
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Bharath-code/git-scope/internal/model"
+)
+
+// Output formats supported by Print
+const (
+	FormatJSON      = "json"
+	FormatNDJSON    = "ndjson"
+	FormatCSV       = "csv"
+	FormatTSV       = "tsv"
+	FormatMarkdown  = "markdown"
+	FormatTable     = "table"
+	FormatPorcelain = "porcelain"
+)
+
+// Formats lists every supported output format
+var Formats = []string{FormatJSON, FormatNDJSON, FormatCSV, FormatTSV, FormatMarkdown, FormatTable, FormatPorcelain}
+
+// IsFormat reports whether format is a supported output format
+func IsFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Field is a named column that can be selected with --fields
+type Field struct {
+	Name  string
+	Value func(r model.Repo) any // string, int, bool or time.Time
+}
+
+// AllFields lists every selectable field in their documented order
+var AllFields = []Field{
+	{"name", func(r model.Repo) any { return r.Name }},
+	{"path", func(r model.Repo) any { return r.Path }},
+	{"kind", func(r model.Repo) any { return string(r.Kind) }},
+	{"main_repo", func(r model.Repo) any { return r.MainRepo }},
+	{"parent", func(r model.Repo) any { return r.Parent }},
+	{"nesting", func(r model.Repo) any { return string(r.Nesting) }},
+	{"aliases", func(r model.Repo) any { return strings.Join(r.Aliases, " ") }},
+	{"branch", func(r model.Repo) any { return r.Status.Branch }},
+	{"upstream", func(r model.Repo) any { return r.Status.Upstream }},
+	{"no_upstream", func(r model.Repo) any { return r.Status.NoUpstream }},
+	{"upstream_gone", func(r model.Repo) any { return r.Status.UpstreamGone }},
+	{"ahead", func(r model.Repo) any { return r.Status.Ahead }},
+	{"behind", func(r model.Repo) any { return r.Status.Behind }},
+	{"unpublished", func(r model.Repo) any { return r.Status.Unpublished }},
+	{"unpushed_branches", func(r model.Repo) any { return len(r.Status.UnpushedBranches) }},
+	{"staged", func(r model.Repo) any { return r.Status.Staged }},
+	{"unstaged", func(r model.Repo) any { return r.Status.Unstaged }},
+	{"untracked", func(r model.Repo) any { return r.Status.Untracked }},
+	{"conflicts", func(r model.Repo) any { return r.Status.Conflicts }},
+	{"operation", func(r model.Repo) any { return string(r.Status.Operation) }},
+	{"stashes", func(r model.Repo) any { return r.Status.Stashes }},
+	{"submodules", func(r model.Repo) any { return len(r.Status.Submodules) }},
+	{"dirty", func(r model.Repo) any { return r.Status.IsDirty }},
+	{"last_commit", func(r model.Repo) any { return r.Status.LastCommit }},
+	{"error", func(r model.Repo) any { return r.Status.ScanError }},
+	{"branches", func(r model.Repo) any {
+		if r.Status.Bare == nil {
+			return 0
+		}
+		return len(r.Status.Bare.Branches)
+	}},
+	{"size_bytes", func(r model.Repo) any {
+		if r.Status.Bare == nil {
+			return 0
+		}
+		return int(r.Status.Bare.SizeBytes)
+	}},
+	{"last_fetch", func(r model.Repo) any {
+		if r.Status.Bare == nil {
+			return time.Time{}
+		}
+		return r.Status.Bare.LastFetch
+	}},
+}
+
+// defaultFields are the columns of the tabular formats when --fields is not given
+var defaultFields = []string{"name", "branch", "staged", "unstaged", "untracked", "ahead", "behind", "dirty", "last_commit", "path"}
+
+// porcelainFields are the columns of the porcelain format when --fields
+// is not given. This list is part of the documented porcelain contract:
+// new fields may only ever be appended.
+var porcelainFields = []string{"path", "branch", "upstream", "ahead", "behind", "staged", "unstaged", "untracked", "conflicts", "stashes", "dirty", "last_commit"}
+
+// ParseFields resolves a comma separated list of field names.
+// An empty spec returns nil, meaning the format's default fields.
+func ParseFields(spec string) ([]Field, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	byName := make(map[string]Field, len(AllFields))
+	for _, f := range AllFields {
+		byName[f.Name] = f
+	}
+
+	var fields []Field
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		f, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q (available: %s)", name, strings.Join(fieldNames(AllFields), ", "))
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// Print writes repos in the given format. If fields is nil, JSON formats
+// print complete repo objects and tabular formats print their default columns.
+func Print(w io.Writer, repos []model.Repo, format string, fields []Field) error {
+	switch format {
+	case FormatJSON:
+		if fields == nil {
+			return PrintJSON(w, repos)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(selectFields(repos, fields))
+
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		for i, r := range repos {
+			var v any = r
+			if fields != nil {
+				v = selectFields(repos[i:i+1], fields)[0]
+			}
+			if err := enc.Encode(v); err != nil {
+				return fmt.Errorf("encode json: %w", err)
+			}
+		}
+		return nil
+
+	case FormatCSV, FormatTSV:
+		return printDelimited(w, repos, withDefaults(fields, defaultFields), format == FormatTSV)
+
+	case FormatMarkdown:
+		return printMarkdown(w, repos, withDefaults(fields, defaultFields))
+
+	case FormatTable:
+		return printTable(w, repos, withDefaults(fields, defaultFields))
+
+	case FormatPorcelain:
+		return printPorcelain(w, repos, withDefaults(fields, porcelainFields))
+	}
+
+	return fmt.Errorf("unknown format %q (available: %s)", format, strings.Join(Formats, ", "))
+}
+
+// orderedFields is a JSON object that keeps the order of the selected fields
+type orderedFields struct {
+	fields []Field
+	repo   model.Repo
+}
+
+// MarshalJSON encodes the selected fields in selection order
+func (o orderedFields) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteString("{")
+	for i, f := range o.fields {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		key, err := json.Marshal(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(f.Value(o.repo))
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteString(":")
+		b.Write(val)
+	}
+	b.WriteString("}")
+	return []byte(b.String()), nil
+}
+
+// selectFields projects repos onto the selected fields for JSON output
+func selectFields(repos []model.Repo, fields []Field) []orderedFields {
+	out := make([]orderedFields, 0, len(repos))
+	for _, r := range repos {
+		out = append(out, orderedFields{fields: fields, repo: r})
+	}
+	return out
+}
+
+// printDelimited writes a header row followed by one row per repo as CSV,
+// or as TSV if tabs is true
+func printDelimited(w io.Writer, repos []model.Repo, fields []Field, tabs bool) error {
+	cw := csv.NewWriter(w)
+	if tabs {
+		cw.Comma = '\t'
+	}
+
+	if err := cw.Write(fieldNames(fields)); err != nil {
+		return err
+	}
+	for _, r := range repos {
+		if err := cw.Write(textValues(r, fields)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// printMarkdown writes a GitHub-flavored markdown table
+func printMarkdown(w io.Writer, repos []model.Repo, fields []Field) error {
+	names := fieldNames(fields)
+	sep := make([]string, len(names))
+	for i := range sep {
+		sep[i] = "---"
+	}
+
+	if _, err := fmt.Fprintf(w, "| %s |\n| %s |\n", strings.Join(names, " | "), strings.Join(sep, " | ")); err != nil {
+		return err
+	}
+	for _, r := range repos {
+		values := textValues(r, fields)
+		for i, v := range values {
+			values[i] = strings.ReplaceAll(v, "|", `\|`)
+		}
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(values, " | ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printTable writes a plain, space-aligned text table
+func printTable(w io.Writer, repos []model.Repo, fields []Field) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	names := fieldNames(fields)
+	for i, n := range names {
+		names[i] = strings.ToUpper(n)
+	}
+	fmt.Fprintln(tw, strings.Join(names, "\t"))
+
+	for _, r := range repos {
+		values := textValues(r, fields)
+		for i, v := range values {
+			if v == "" {
+				values[i] = "-"
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+	return tw.Flush()
+}
+
+// printPorcelain writes the stable scripting format: one line per repo,
+// no header, fields separated by a single tab. Empty values are "-",
+// booleans are 1/0, times are unix seconds (0 if unknown), and values
+// containing a tab, newline or quote are C-quoted.
+func printPorcelain(w io.Writer, repos []model.Repo, fields []Field) error {
+	for _, r := range repos {
+		values := make([]string, len(fields))
+		for i, f := range fields {
+			values[i] = porcelainValue(f.Value(r))
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(values, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// porcelainValue formats a single field value for the porcelain format
+func porcelainValue(v any) string {
+	switch val := v.(type) {
+	case bool:
+		if val {
+			return "1"
+		}
+		return "0"
+	case time.Time:
+		if val.IsZero() {
+			return "0"
+		}
+		return strconv.FormatInt(val.Unix(), 10)
+	case string:
+		if val == "" {
+			return "-"
+		}
+		if strings.ContainsAny(val, "\t\n\"") {
+			return strconv.Quote(val)
+		}
+		return val
+	}
+	return fmt.Sprint(v)
+}
+
+// textValues formats the field values of a repo for human-oriented formats
+func textValues(r model.Repo, fields []Field) []string {
+	values := make([]string, len(fields))
+	for i, f := range fields {
+		switch val := f.Value(r).(type) {
+		case time.Time:
+			if !val.IsZero() {
+				values[i] = val.Format(time.RFC3339)
+			}
+		default:
+			values[i] = fmt.Sprint(val)
+		}
+	}
+	return values
+}
+
+// withDefaults returns fields, or the named default fields if none were selected
+func withDefaults(fields []Field, defaults []string) []Field {
+	if fields != nil {
+		return fields
+	}
+	resolved, _ := ParseFields(strings.Join(defaults, ","))
+	return resolved
+}
+
+// fieldNames returns the names of the given fields
+func fieldNames(fields []Field) []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return names
+}
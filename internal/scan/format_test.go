@@ -0,0 +1,143 @@
+package scan
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bharath-code/git-scope/internal/model"
+)
+
+// formatRepos are two repos covering empty values, booleans, times and
+// values that need quoting
+func formatRepos() []model.Repo {
+	return []model.Repo{
+		{
+			Name: "api",
+			Path: "/code/api",
+			Status: model.RepoStatus{
+				Branch:     "main",
+				Upstream:   "origin/main",
+				Ahead:      2,
+				Staged:     1,
+				Unstaged:   3,
+				IsDirty:    true,
+				LastCommit: time.Unix(1700000000, 0).UTC(),
+			},
+		},
+		{
+			Name: `odd "name", | piped`,
+			Path: "/code/odd\tpath",
+			Status: model.RepoStatus{
+				Branch: "feature/x",
+			},
+		},
+	}
+}
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		fields string
+		want   string
+	}{
+		{
+			name:   "porcelain default columns",
+			format: FormatPorcelain,
+			want: "/code/api\tmain\torigin/main\t2\t0\t1\t3\t0\t0\t0\t1\t1700000000\n" +
+				"\"/code/odd\\tpath\"\tfeature/x\t-\t0\t0\t0\t0\t0\t0\t0\t0\t0\n",
+		},
+		{
+			name:   "porcelain selected fields",
+			format: FormatPorcelain,
+			fields: "name,dirty,upstream",
+			want: "api\t1\torigin/main\n" +
+				"\"odd \\\"name\\\", | piped\"\t0\t-\n",
+		},
+		{
+			name:   "csv",
+			format: FormatCSV,
+			fields: "name,branch,last_commit,dirty",
+			want: "name,branch,last_commit,dirty\n" +
+				"api,main,2023-11-14T22:13:20Z,true\n" +
+				"\"odd \"\"name\"\", | piped\",feature/x,,false\n",
+		},
+		{
+			name:   "tsv",
+			format: FormatTSV,
+			fields: "path,ahead",
+			want: "path\tahead\n" +
+				"/code/api\t2\n" +
+				"\"/code/odd\tpath\"\t0\n",
+		},
+		{
+			name:   "markdown",
+			format: FormatMarkdown,
+			fields: "name,upstream",
+			want: "| name | upstream |\n| --- | --- |\n" +
+				"| api | origin/main |\n" +
+				"| odd \"name\", \\| piped |  |\n",
+		},
+		{
+			name:   "table",
+			format: FormatTable,
+			fields: "branch,upstream",
+			want: "BRANCH     UPSTREAM\n" +
+				"main       origin/main\n" +
+				"feature/x  -\n",
+		},
+		{
+			name:   "json selected fields keep their order",
+			format: FormatJSON,
+			fields: "dirty,name",
+			want: "[\n  {\n    \"dirty\": true,\n    \"name\": \"api\"\n  },\n" +
+				"  {\n    \"dirty\": false,\n    \"name\": \"odd \\\"name\\\", | piped\"\n  }\n]\n",
+		},
+		{
+			name:   "ndjson selected fields",
+			format: FormatNDJSON,
+			fields: "name,ahead,last_commit",
+			want: "{\"name\":\"api\",\"ahead\":2,\"last_commit\":\"2023-11-14T22:13:20Z\"}\n" +
+				"{\"name\":\"odd \\\"name\\\", | piped\",\"ahead\":0,\"last_commit\":\"0001-01-01T00:00:00Z\"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := ParseFields(tt.fields)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := Print(&buf, formatRepos(), tt.format, fields); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output differs\ngot:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPorcelainFieldsStable pins the default porcelain columns, which
+// scripts rely on: new fields may only be appended
+func TestPorcelainFieldsStable(t *testing.T) {
+	want := "path,branch,upstream,ahead,behind,staged,unstaged,untracked,conflicts,stashes,dirty,last_commit"
+	if got := strings.Join(porcelainFields, ","); !strings.HasPrefix(got, want) {
+		t.Errorf("porcelain columns changed:\ngot  %s\nwant %s", got, want)
+	}
+	if _, err := ParseFields(strings.Join(porcelainFields, ",")); err != nil {
+		t.Errorf("porcelain columns name an unknown field: %v", err)
+	}
+}
+
+func TestPrintErrors(t *testing.T) {
+	if _, err := ParseFields("name,nope"); err == nil || !strings.Contains(err.Error(), `unknown field "nope"`) {
+		t.Errorf("ParseFields with an unknown field: %v", err)
+	}
+	if err := Print(&bytes.Buffer{}, formatRepos(), "yaml", nil); err == nil || !strings.Contains(err.Error(), `unknown format "yaml"`) {
+		t.Errorf("Print with an unknown format: %v", err)
+	}
+}
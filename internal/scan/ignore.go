@@ -0,0 +1,541 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Bharath-code/git-scope/internal/config"
+	"github.com/Bharath-code/git-scope/internal/gitstatus"
+)
+
+// IgnoreFile is the name of the marker file that excludes a directory
+// from scans. An empty file excludes the directory itself; otherwise each
+// line is an ignore pattern for the directories below it, written like
+// the ignore list of the config but relative to the file's directory.
+const IgnoreFile = ".git-scope-ignore"
+
+// ignoreRule is a single ignore pattern and where it came from
+type ignoreRule struct {
+	pattern string // As written, for Explain
+	source  string // "smart default", "config ignore" or an ignore file path
+	negate  bool   // "!pattern" re-includes what earlier rules excluded
+
+	// A pattern without a slash is matched against the directory name.
+	// Others are matched against the path relative to base, segment by
+	// segment, where "**" stands for any number of directories.
+	base     string
+	segments []string
+}
+
+// ignoreRules are applied in order and the last matching rule decides,
+// as in .gitignore
+type ignoreRules []ignoreRule
+
+// newRule parses a pattern. Anchored patterns are relative to base unless
+// absolute ("/..." in config, "~/...") and allowAbsolute is set.
+func newRule(pattern, source, base string, allowAbsolute bool) (ignoreRule, bool) {
+	r := ignoreRule{pattern: pattern, source: source}
+	p := strings.TrimSpace(pattern)
+	if strings.HasPrefix(p, "!") {
+		r.negate = true
+		p = p[1:]
+	}
+	p = strings.TrimSuffix(filepath.ToSlash(p), "/")
+	if p == "" {
+		return r, false
+	}
+	if !strings.Contains(p, "/") {
+		r.segments = []string{p}
+		return r, true
+	}
+
+	switch {
+	case allowAbsolute && strings.HasPrefix(p, "~/"):
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return r, false
+		}
+		r.base = "/"
+		p = strings.TrimPrefix(filepath.ToSlash(home), "/") + p[1:]
+	case allowAbsolute && strings.HasPrefix(p, "/"):
+		r.base = "/"
+	default:
+		r.base = filepath.ToSlash(base)
+	}
+	r.segments = strings.Split(strings.TrimPrefix(p, "/"), "/")
+	return r, true
+}
+
+// matches reports whether the rule matches a directory
+func (r ignoreRule) matches(dir string) bool {
+	if r.base == "" {
+		ok, _ := path.Match(r.segments[0], filepath.Base(dir))
+		return ok
+	}
+	segs, ok := r.relative(dir)
+	return ok && matchSegments(r.segments, segs)
+}
+
+// leadsTo reports whether an anchored rule can match directories below
+// dir, so the walker has to pass through it to reach them
+func (r ignoreRule) leadsTo(dir string) bool {
+	segs, ok := r.relative(dir)
+	if r.base == "" || !ok {
+		return false
+	}
+	for i, seg := range segs {
+		if i >= len(r.segments) {
+			return false
+		}
+		if r.segments[i] == "**" {
+			return true
+		}
+		if ok, _ := path.Match(r.segments[i], seg); !ok {
+			return false
+		}
+	}
+	return len(segs) < len(r.segments)
+}
+
+// relative splits the path of dir below the rule's base into segments
+func (r ignoreRule) relative(dir string) ([]string, bool) {
+	dir = filepath.ToSlash(dir)
+	rel := strings.TrimPrefix(dir, "/")
+	if r.base != "/" {
+		prefix := strings.TrimSuffix(r.base, "/") + "/"
+		if !strings.HasPrefix(dir, prefix) {
+			return nil, false
+		}
+		rel = dir[len(prefix):]
+	}
+	return strings.Split(rel, "/"), true
+}
+
+// matchSegments matches path segments against pattern segments, with
+// "**" matching zero or more segments
+func matchSegments(pattern, segs []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segs); i++ {
+				if matchSegments(pattern[1:], segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segs[0]); !ok {
+			return false
+		}
+		pattern, segs = pattern[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
+
+// match returns the rule that decides whether a directory is excluded,
+// or nil if no rule matches
+func (rs ignoreRules) match(dir string) *ignoreRule {
+	for i := len(rs) - 1; i >= 0; i-- {
+		if rs[i].matches(dir) {
+			return &rs[i]
+		}
+	}
+	return nil
+}
+
+// leadsToInclude reports whether an include is anchored below dir
+func (rs ignoreRules) leadsToInclude(dir string) bool {
+	for _, r := range rs {
+		if r.negate && r.leadsTo(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// rootRules returns the rules for a root in the order they apply: the
+// preset, the global ignore and include lists, then those of the root
+func rootRules(root config.Root, opts Options) ignoreRules {
+	var rules ignoreRules
+	add := func(patterns []string, source string, include bool) {
+		for _, p := range patterns {
+			if r, ok := newRule(p, source, root.Path, true); ok {
+				r.negate = r.negate || include
+				rules = append(rules, r)
+			}
+		}
+	}
+	add(opts.Preset, "ignore preset", false)
+	add(opts.Ignore, "config ignore", false)
+	add(opts.Include, "config include", true)
+	add(root.Ignore, "root ignore", false)
+	add(root.Include, "root include", true)
+	return rules
+}
+
+// readIgnoreFile checks a directory's entries for an IgnoreFile. It
+// reports exclude if the file is empty, and otherwise returns rules
+// extended by its patterns.
+func readIgnoreFile(dir string, entries []os.DirEntry, rules ignoreRules) (ignoreRules, bool) {
+	for _, e := range entries {
+		if e.Name() != IgnoreFile || e.IsDir() {
+			continue
+		}
+		file := filepath.Join(dir, IgnoreFile)
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return rules, false
+		}
+
+		var added ignoreRules
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if r, ok := newRule(line, file, dir, false); ok {
+				added = append(added, r)
+			}
+		}
+		if len(added) == 0 {
+			return rules, true
+		}
+		// Copy so sibling directories don't share the appended rules
+		out := make(ignoreRules, len(rules), len(rules)+len(added))
+		copy(out, rules)
+		return append(out, added...), false
+	}
+	return rules, false
+}
+
+// discovery hands the repos found by all walkers to the status stage
+type discovery struct {
+	ctx      context.Context
+	found    chan<- string
+	progress *Progress
+
+	// Symlinks, overlapping roots and listed repos can reach a repo more
+	// than once. Repos are sent under their real path, once.
+	mu       sync.Mutex
+	aliases  map[string][]string // Real path to the other paths it was reached by
+	byGitDir map[string]string   // Real git directory to real path
+}
+
+func newDiscovery(ctx context.Context, found chan<- string, progress *Progress) *discovery {
+	return &discovery{
+		ctx:      ctx,
+		found:    found,
+		progress: progress,
+		aliases:  make(map[string][]string),
+		byGitDir: make(map[string]string),
+	}
+}
+
+// send passes a repo on under its real path, unless that path or its git
+// directory was sent before. The path it was reached by is recorded as
+// an alias when it differs.
+func (d *discovery) send(reached string) error {
+	repoPath := realPath(reached)
+	gitDir := ""
+	if layout, err := gitstatus.ResolveLayout(repoPath); err == nil {
+		gitDir = realPath(layout.GitDir)
+	}
+
+	d.mu.Lock()
+	aliases, dup := d.aliases[repoPath]
+	if !dup && gitDir != "" {
+		// The same repo under a path EvalSymlinks can't see through,
+		// such as a bind mount
+		if first, ok := d.byGitDir[gitDir]; ok {
+			repoPath = first
+			aliases, dup = d.aliases[first], true
+		}
+	}
+	if reached != repoPath && !containsString(aliases, reached) {
+		aliases = append(aliases, reached)
+	}
+	d.aliases[repoPath] = aliases
+	if !dup && gitDir != "" {
+		d.byGitDir[gitDir] = repoPath
+	}
+	d.mu.Unlock()
+	if dup {
+		return nil
+	}
+
+	d.progress.addFound()
+	select {
+	case d.found <- repoPath:
+		return nil
+	case <-d.ctx.Done():
+		return d.ctx.Err()
+	}
+}
+
+// aliasesOf returns the paths other than its real path a repo was
+// reached by so far
+func (d *discovery) aliasesOf(repoPath string) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	aliases := d.aliases[repoPath]
+	if len(aliases) == 0 {
+		return nil
+	}
+	out := make([]string, len(aliases))
+	copy(out, aliases)
+	sort.Strings(out)
+	return out
+}
+
+// realPath resolves the symlinks in a path, keeping it as is if that fails
+func realPath(path string) string {
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		return real
+	}
+	return path
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// walker finds the working trees below a root
+type walker struct {
+	*discovery
+	root        config.Root
+	stopAtRepos bool
+}
+
+// walk visits a directory at the given depth below the root and sends
+// every working tree it finds. Nested repos, such as submodule
+// checkouts, are found too. Unreadable directories are skipped.
+//
+// Symlinks to directories are followed. chain holds the real paths of dir
+// and the directories above it, so a symlink leading back to one of them
+// is recognized as a loop and skipped.
+//
+// A passing directory is excluded itself but leads to an include pattern
+// anchored below it, so only the way to that include is walked.
+func (w *walker) walk(dir string, chain []string, depth int, rules ignoreRules, passing bool) error {
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	w.progress.addDir()
+
+	rules, excluded := readIgnoreFile(dir, entries, rules)
+	if excluded {
+		return nil
+	}
+
+	// Bare repositories, by convention named *.git, have no working tree
+	// to search. A root may be one whatever its name.
+	if (depth == 0 || strings.HasSuffix(dir, ".git")) && gitstatus.IsBare(dir) {
+		if passing {
+			return nil
+		}
+		return w.send(dir)
+	}
+
+	// A .git directory, or a .git file used by linked worktrees and
+	// submodule checkouts
+	for _, e := range entries {
+		if e.Name() == ".git" && !passing {
+			if err := w.send(dir); err != nil {
+				return err
+			}
+			// A root that is a repo itself was named explicitly, so
+			// it is searched either way
+			if w.stopAtRepos && depth > 0 {
+				return nil
+			}
+			break
+		}
+	}
+
+	if w.root.MaxDepth > 0 && depth >= w.root.MaxDepth {
+		return nil
+	}
+	real := chain[len(chain)-1]
+	for _, e := range entries {
+		if e.Name() == ".git" {
+			continue
+		}
+		child := filepath.Join(dir, e.Name())
+		childReal := filepath.Join(real, e.Name())
+		switch {
+		case e.IsDir():
+		case e.Type()&fs.ModeSymlink != 0:
+			info, err := os.Stat(child)
+			if err != nil || !info.IsDir() {
+				continue
+			}
+			childReal = realPath(child)
+			if isLoop(childReal, chain) {
+				continue
+			}
+		default:
+			continue
+		}
+
+		r := rules.match(child)
+		childPassing := false
+		switch {
+		case r != nil && r.negate:
+		case r == nil && !passing:
+		case rules.leadsToInclude(child):
+			childPassing = true
+		default:
+			continue
+		}
+		if err := w.walk(child, append(chain, childReal), depth+1, rules, childPassing); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isLoop reports whether walking target would lead back into the chain
+// of directories being walked: it is one of them, or above one of them
+func isLoop(target string, chain []string) bool {
+	prefix := strings.TrimSuffix(target, string(filepath.Separator)) + string(filepath.Separator)
+	for _, dir := range chain {
+		if dir == target || strings.HasPrefix(dir, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Explanation tells whether scans enter a directory, and if not, why
+type Explanation struct {
+	Path     string
+	Root     string // Root the path is under, empty if none
+	Listed   bool   // Path is in Options.Repos
+	Excluded bool
+	Dir      string // The excluded directory: Path or one of its parents
+	Reason   string
+}
+
+// String formats the explanation for the command line
+func (e Explanation) String() string {
+	switch {
+	case e.Listed:
+		return fmt.Sprintf("%s: scanned (listed in repos)", e.Path)
+	case e.Root == "":
+		return fmt.Sprintf("%s: not scanned, %s", e.Path, e.Reason)
+	case !e.Excluded:
+		return fmt.Sprintf("%s: scanned (root %s)", e.Path, e.Root)
+	case e.Dir != e.Path:
+		return fmt.Sprintf("%s: not scanned, parent %s is excluded by %s", e.Path, e.Dir, e.Reason)
+	default:
+		return fmt.Sprintf("%s: not scanned, excluded by %s", e.Path, e.Reason)
+	}
+}
+
+// Explain reports whether the walker would enter a directory, checking
+// the same rules as a scan on the way down from its root
+func Explain(opts Options, dir string) Explanation {
+	dir = filepath.Clean(absPath(expandPath(dir)))
+	e := Explanation{Path: dir}
+	for _, repo := range opts.Repos {
+		if filepath.Clean(absPath(expandPath(repo))) == dir {
+			e.Listed = true
+			return e
+		}
+	}
+
+	// Roots can overlap: the path is scanned if any root reaches it,
+	// otherwise the most specific root explains why not
+	for _, r := range opts.Roots {
+		r.Path = filepath.Clean(absPath(expandPath(r.Path)))
+		if dir != r.Path && !strings.HasPrefix(dir, r.Path+string(filepath.Separator)) {
+			continue
+		}
+		re := explainRoot(opts, r, dir)
+		if e.Root == "" || (e.Excluded && (!re.Excluded || len(re.Root) > len(e.Root))) {
+			e = re
+		}
+		if !e.Excluded {
+			break
+		}
+	}
+	if e.Root == "" {
+		e.Reason = "it is not below any root"
+	}
+	return e
+}
+
+// explainRoot follows the walk from a root down to dir
+func explainRoot(opts Options, root config.Root, dir string) Explanation {
+	e := Explanation{Path: dir, Root: root.Path}
+	rel, _ := filepath.Rel(root.Path, dir)
+	parts := []string{}
+	if rel != "." {
+		parts = strings.Split(rel, string(filepath.Separator))
+	}
+
+	rules := rootRules(root, opts)
+	current := root.Path
+	passing := false
+	for depth := 0; ; depth++ {
+		entries, _ := os.ReadDir(current)
+		var excluded bool
+		if rules, excluded = readIgnoreFile(current, entries, rules); excluded {
+			e.Excluded, e.Dir = true, current
+			e.Reason = "the empty " + filepath.Join(current, IgnoreFile)
+			return e
+		}
+		if depth == len(parts) {
+			// Passed through on the way to an include, but not scanned
+			e.Excluded = passing
+			return e
+		}
+		if opts.StopAtRepos && depth > 0 && !passing {
+			if _, err := os.Lstat(filepath.Join(current, ".git")); err == nil {
+				e.Excluded, e.Dir = true, current
+				e.Reason = "being a repo, as descendIntoRepos is off"
+				return e
+			}
+		}
+
+		if root.MaxDepth > 0 && depth >= root.MaxDepth {
+			e.Excluded, e.Dir = true, filepath.Join(current, parts[depth])
+			e.Reason = fmt.Sprintf("maxDepth %d of root %s", root.MaxDepth, e.Root)
+			return e
+		}
+		current = filepath.Join(current, parts[depth])
+		r := rules.match(current)
+		switch {
+		case r != nil && r.negate:
+			passing, e.Dir, e.Reason = false, "", ""
+			continue
+		case r == nil && !passing:
+			continue
+		case r != nil && !passing:
+			e.Dir, e.Reason = current, fmt.Sprintf("pattern %q (%s)", r.pattern, r.source)
+		}
+		if !rules.leadsToInclude(current) {
+			e.Excluded = true
+			return e
+		}
+		passing = true
+	}
+}
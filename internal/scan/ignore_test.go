@@ -0,0 +1,244 @@
+package scan
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/Bharath-code/git-scope/internal/config"
+)
+
+func TestIgnoreRuleMatches(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test paths are POSIX paths")
+	}
+	t.Setenv("HOME", "/home/u")
+
+	tests := []struct {
+		pattern string
+		dir     string
+		want    bool
+	}{
+		// Names match whole directory names, at any depth
+		{"build", "/r/build", true},
+		{"build", "/r/a/b/build", true},
+		{"build", "/r/my-build", false},
+		{"build", "/r/rebuild", false},
+		{"build", "/r/build-tools", false},
+		{"*.tmp", "/r/cache.tmp", true},
+		{"*.tmp", "/r/tmp", false},
+
+		// Paths are anchored to the root, or absolute
+		{"archive/old", "/r/archive/old", true},
+		{"archive/old", "/r/x/archive/old", false},
+		{"archive/old", "/r/archive/old/deeper", false},
+		{"/srv/mirrors", "/srv/mirrors", true},
+		{"/srv/mirrors", "/r/srv/mirrors", false},
+
+		// ** spans any number of directories, none included
+		{"~/code/archive/**", "/home/u/code/archive", true},
+		{"~/code/archive/**", "/home/u/code/archive/old-api", true},
+		{"~/code/archive/**", "/home/u/code/archive/2019/old-api", true},
+		{"~/code/archive/**", "/home/u/code/archived", false},
+		{"~/code/archive/**", "/home/u/other/code/archive/x", false},
+		{"a/**/z", "/r/a/z", true},
+		{"a/**/z", "/r/a/b/c/z", true},
+		{"a/**/z", "/r/a/b/c/y", false},
+	}
+
+	for _, tt := range tests {
+		r, ok := newRule(tt.pattern, "test", "/r", true)
+		if !ok {
+			t.Fatalf("newRule(%q) rejected the pattern", tt.pattern)
+		}
+		if got := r.matches(tt.dir); got != tt.want {
+			t.Errorf("%q matches %s = %v, want %v", tt.pattern, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestIgnoreRulesLastMatchWins(t *testing.T) {
+	var rules ignoreRules
+	for _, p := range []string{"vendor", "!vendor", "cache"} {
+		r, _ := newRule(p, "test", "/r", true)
+		rules = append(rules, r)
+	}
+	if r := rules.match("/r/vendor"); r == nil || !r.negate {
+		t.Errorf("vendor: got %+v, want the re-include", r)
+	}
+	if r := rules.match("/r/cache"); r == nil || r.negate {
+		t.Errorf("cache: got %+v, want the ignore", r)
+	}
+	if r := rules.match("/r/src"); r != nil {
+		t.Errorf("src: got %+v, want no match", r)
+	}
+}
+
+// mkdirs creates directories, and files for paths with content
+func mkdirs(t *testing.T, root string, dirs []string, files map[string]string) {
+	t.Helper()
+	for _, d := range dirs {
+		if err := os.MkdirAll(filepath.Join(root, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root,
+		[]string{
+			"archive/keep", "archive/old",
+			"deep/a/b",
+			"skip/inner",
+			"proj/tmp", "proj/out", "proj/src/out",
+		},
+		map[string]string{
+			"skip/" + IgnoreFile: "",
+			"proj/" + IgnoreFile: "# generated\ntmp\n/out\n",
+		})
+	other := t.TempDir()
+	mkdirs(t, other, []string{"a/b"}, nil)
+
+	opts := Options{
+		Roots: []config.Root{
+			{Path: root},
+			{Path: other, MaxDepth: 1},
+		},
+		Ignore:  []string{"archive"},
+		Include: []string{filepath.Join(root, "archive/keep")},
+	}
+
+	tests := []struct {
+		dir      string
+		excluded bool
+		excDir   string // Directory the exclusion is reported for
+		reason   string
+	}{
+		{dir: "archive/old", excluded: true, excDir: "archive", reason: `pattern "archive" (config ignore)`},
+		{dir: "archive", excluded: true, excDir: "archive", reason: `pattern "archive" (config ignore)`},
+		{dir: "archive/keep"},
+
+		{dir: "skip", excluded: true, excDir: "skip", reason: "the empty " + filepath.Join(root, "skip", IgnoreFile)},
+		{dir: "skip/inner", excluded: true, excDir: "skip", reason: "the empty " + filepath.Join(root, "skip", IgnoreFile)},
+
+		{dir: "proj"},
+		{dir: "proj/src"},
+		{dir: "proj/tmp", excluded: true, excDir: "proj/tmp", reason: `pattern "tmp" (` + filepath.Join(root, "proj", IgnoreFile) + ")"},
+		{dir: "proj/out", excluded: true, excDir: "proj/out", reason: `pattern "/out" (` + filepath.Join(root, "proj", IgnoreFile) + ")"},
+		{dir: "proj/src/out"},
+
+		{dir: "deep/a/b"},
+	}
+	for _, tt := range tests {
+		e := Explain(opts, filepath.Join(root, tt.dir))
+		if e.Excluded != tt.excluded {
+			t.Errorf("%s: excluded = %v, want %v (%s)", tt.dir, e.Excluded, tt.excluded, e)
+			continue
+		}
+		if !tt.excluded {
+			continue
+		}
+		if want := filepath.Join(root, tt.excDir); e.Dir != want || e.Reason != tt.reason {
+			t.Errorf("%s: excluded at %s by %s, want %s by %s", tt.dir, e.Dir, e.Reason, want, tt.reason)
+		}
+	}
+
+	// maxDepth 1 reaches repos directly in the root only
+	if e := Explain(opts, filepath.Join(other, "a")); e.Excluded {
+		t.Errorf("maxDepth 1: %s", e)
+	}
+	e := Explain(opts, filepath.Join(other, "a", "b"))
+	if want := "maxDepth 1 of root " + other; !e.Excluded || e.Reason != want {
+		t.Errorf("maxDepth 1: got %s, want excluded by %s", e, want)
+	}
+
+	if e := Explain(opts, t.TempDir()); e.Root != "" || e.Reason != "it is not below any root" {
+		t.Errorf("outside the roots: %s", e)
+	}
+}
+
+func TestIsLoop(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test paths are POSIX paths")
+	}
+	chain := []string{"/r", "/r/a", "/r/a/b"}
+	tests := []struct {
+		target string
+		want   bool
+	}{
+		{"/r", true},     // back to the root
+		{"/r/a", true},   // back to a parent
+		{"/r/a/b", true}, // to itself
+		{"/", true},      // above the root, which leads back down into it
+		{"/r/a/c", false},
+		{"/r/ab", false},
+		{"/other", false},
+	}
+	for _, tt := range tests {
+		if got := isLoop(tt.target, chain); got != tt.want {
+			t.Errorf("isLoop(%s) = %v, want %v", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestDiscoveryDedup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fixture uses symlinks")
+	}
+	root := t.TempDir()
+	mkdirs(t, root, []string{"repo/.git", "gitdirs/shared", "mount-a", "mount-b"},
+		map[string]string{
+			"mount-a/.git": "gitdir: ../gitdirs/shared\n",
+			"mount-b/.git": "gitdir: ../gitdirs/shared\n",
+		})
+	if err := os.Symlink(filepath.Join(root, "repo"), filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+	real := realPath(root)
+
+	found := make(chan string, 8)
+	d := newDiscovery(context.Background(), found, nil)
+	for _, reached := range []string{
+		filepath.Join(root, "repo"),
+		filepath.Join(root, "link"),
+		filepath.Join(root, "link"),
+		// Two paths for one git dir, as a bind mount looks
+		filepath.Join(root, "mount-a"),
+		filepath.Join(root, "mount-b"),
+	} {
+		if err := d.send(reached); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(found)
+
+	var sent []string
+	for p := range found {
+		sent = append(sent, p)
+	}
+	want := []string{filepath.Join(real, "repo"), filepath.Join(real, "mount-a")}
+	if !reflect.DeepEqual(sent, want) {
+		t.Errorf("sent %v, want %v", sent, want)
+	}
+
+	aliases := d.aliasesOf(filepath.Join(real, "repo"))
+	wantAliases := []string{filepath.Join(root, "link")}
+	if real != root {
+		wantAliases = []string{filepath.Join(root, "link"), filepath.Join(root, "repo")}
+	}
+	if !reflect.DeepEqual(aliases, wantAliases) {
+		t.Errorf("repo aliases = %v, want %v", aliases, wantAliases)
+	}
+	if got := d.aliasesOf(filepath.Join(real, "mount-a")); !containsString(got, filepath.Join(root, "mount-b")) {
+		t.Errorf("mount-a aliases = %v, want mount-b among them", got)
+	}
+}
@@ -1,136 +1,402 @@
 package scan
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/Bharath-code/git-scope/internal/config"
 	"github.com/Bharath-code/git-scope/internal/gitstatus"
 	"github.com/Bharath-code/git-scope/internal/model"
 )
 
-// smartIgnorePatterns are always-ignored directories for performance
-// These are system/tool directories that should never contain user repos
-var smartIgnorePatterns = []string{
-	// macOS/Linux system directories
-	"Library", ".Trash", ".cache", ".local",
-	// Package managers & runtimes
-	".npm", ".yarn", ".pnpm", ".bun", ".cargo", ".rustup", ".go",
-	".venv", ".pyenv", ".rbenv", ".nvm", ".sdkman",
-	// IDE extensions (contain third-party repos, not your code)
-	".vscode", ".vscode-server", ".cursor", ".zed", ".idea", ".atom",
-	// Shell & tools configs
-	".oh-my-zsh", ".tmux", ".vim", ".emacs.d", ".gemini",
-	// Docker/Cloud
-	".docker", ".kube", ".ssh", ".gnupg",
-	// Cloud sync (slow and likely duplicates)
-	"Google Drive", "OneDrive", "Dropbox", "iCloud",
+// Options configures a scan
+type Options struct {
+	Roots []config.Root
+
+	// Preset, Ignore and Include are the ignore rules of the config,
+	// applied in that order before the rules of each root
+	Preset  []string
+	Ignore  []string
+	Include []string
+
+	// Repos are scanned whatever the ignore rules
+	Repos []string
+
+	// StopAtRepos keeps the walk out of the working trees of the repos it
+	// finds, so repos nested in them, submodules included, are missed
+	StopAtRepos bool
+
+	// Concurrency is the maximum number of repos whose status is
+	// collected at the same time. Zero means DefaultConcurrency().
+	Concurrency int
+
+	// Timeout bounds the git calls for a single repo. Zero means
+	// DefaultRepoTimeout.
+	Timeout time.Duration
+
+	// Provider reads the status of each repo. Nil means running git.
+	Provider gitstatus.StatusProvider
+
+	// Known holds repos from an earlier scan by path, see ByPath. A repo
+	// whose fingerprint still matches is delivered as it was instead of
+	// collecting its status again.
+	Known map[string]model.Repo
+
+	// Progress, if set, is updated as the scan runs
+	Progress *Progress
+}
+
+// DefaultRepoTimeout is how long git may take on a single repo before it
+// is killed and the repo is reported as timed out
+const DefaultRepoTimeout = 30 * time.Second
+
+// Progress counts the work done by a running scan. It is safe to read
+// from another goroutine while the scan is updating it.
+type Progress struct {
+	dirs   atomic.Int64
+	found  atomic.Int64
+	done   atomic.Int64
+	reused atomic.Int64
+}
+
+// Dirs returns the number of directories walked so far
+func (p *Progress) Dirs() int64 { return p.dirs.Load() }
+
+// Found returns the number of repos discovered so far
+func (p *Progress) Found() int64 { return p.found.Load() }
+
+// Done returns the number of repos whose status has been collected
+func (p *Progress) Done() int64 { return p.done.Load() }
+
+// Reused returns the number of done repos taken unchanged from Options.Known
+func (p *Progress) Reused() int64 { return p.reused.Load() }
+
+// The counters are optional, so increments on a nil Progress are no-ops
+func (p *Progress) addDir() {
+	if p != nil {
+		p.dirs.Add(1)
+	}
+}
+
+func (p *Progress) addFound() {
+	if p != nil {
+		p.found.Add(1)
+	}
+}
+
+func (p *Progress) addDone() {
+	if p != nil {
+		p.done.Add(1)
+	}
+}
+
+func (p *Progress) addReused() {
+	if p != nil {
+		p.reused.Add(1)
+	}
+}
+
+// DefaultConcurrency is the number of parallel status workers used when
+// none is configured. git status is mostly I/O bound, so it runs a few
+// more workers than there are CPUs.
+func DefaultConcurrency() int {
+	return runtime.NumCPU() * 2
+}
+
+// OptionsFor returns the scan options set in a config
+func OptionsFor(cfg *config.Config) Options {
+	return Options{
+		Roots:       cfg.Roots,
+		Preset:      cfg.PresetPatterns(),
+		Ignore:      cfg.Ignore,
+		Include:     cfg.Include,
+		Repos:       cfg.Repos,
+		StopAtRepos: !cfg.DescendIntoRepos,
+		Concurrency: cfg.Concurrency,
+		Timeout:     cfg.RepoTimeout,
+		Provider:    gitstatus.ProviderFor(cfg.Git.Backend),
+	}
 }
 
 // ScanRoots recursively scans the given root directories for git repositories
-// It skips directories matching the ignore patterns
-func ScanRoots(roots, ignore []string) ([]model.Repo, error) {
-	// Build ignore set from user config + smart defaults
-	ignoreSet := make(map[string]struct{}, len(ignore)+len(smartIgnorePatterns))
+// It skips directories matching the ignore patterns or the default preset
+func ScanRoots(ctx context.Context, roots, ignore []string) ([]model.Repo, error) {
+	return Collect(ctx, Options{
+		Roots:  config.RootsFor(roots),
+		Preset: config.IgnorePresets[config.DefaultIgnorePreset],
+		Ignore: ignore,
+	})
+}
+
+// ByPath indexes repos by path for Options.Known
+func ByPath(repos []model.Repo) map[string]model.Repo {
+	known := make(map[string]model.Repo, len(repos))
+	for _, r := range repos {
+		known[r.Path] = r
+	}
+	return known
+}
+
+// Refresh collects the status of a single repo again, with the provider
+// and timeout of opts
+func Refresh(ctx context.Context, opts Options, repoPath string) (model.Repo, error) {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultRepoTimeout
+	}
+	provider := opts.Provider
+	if provider == nil {
+		provider = gitstatus.ExecProvider{}
+	}
 
-	// Add user-defined ignores
-	for _, pattern := range ignore {
-		ignoreSet[pattern] = struct{}{}
+	repo, ok, _ := inspectRepo(ctx, provider, repoPath, findParent(opts.Roots, repoPath), timeout, nil)
+	if !ok {
+		return model.Repo{}, fmt.Errorf("%s is no longer a git repository", repoPath)
 	}
+	return repo, nil
+}
 
-	// Add smart defaults (always apply for performance)
-	for _, pattern := range smartIgnorePatterns {
-		ignoreSet[pattern] = struct{}{}
+// AddRepo appends a repo delivered by Scan, or replaces the earlier
+// delivery of the same repo. index maps paths to positions in repos and
+// is kept up to date.
+func AddRepo(repos []model.Repo, index map[string]int, r model.Repo) []model.Repo {
+	if i, ok := index[r.Path]; ok {
+		repos[i] = r
+		return repos
 	}
+	index[r.Path] = len(repos)
+	return append(repos, r)
+}
 
-	var mu sync.Mutex
+// Collect runs a scan and returns every repo found, sorted by path so
+// output does not depend on which worker finished first
+func Collect(ctx context.Context, opts Options) ([]model.Repo, error) {
 	var repos []model.Repo
-	var wg sync.WaitGroup
+	index := make(map[string]int)
+	err := Scan(ctx, opts, func(r model.Repo) {
+		repos = AddRepo(repos, index, r)
+	})
+	sort.Slice(repos, func(i, j int) bool {
+		return repos[i].Path < repos[j].Path
+	})
+	return repos, err
+}
 
-	for _, root := range roots {
-		// Expand ~ and environment variables
-		root = expandPath(root)
+// Scan walks the roots and streams each repo to onRepo as soon as its
+// status is known. Discovery and status collection run as separate
+// stages: one walker per root finds repos, and a bounded pool of workers
+// runs git on them. onRepo is never called concurrently. Scan returns
+// once every repo has been delivered.
+//
+// Repos are reported under their real path, with the other paths they
+// were reached by in Aliases. A repo reached by another path after it was
+// delivered is delivered again at the end of the scan, replacing the
+// earlier delivery with the same path.
+//
+// Cancelling ctx stops the walk and kills running git processes; Scan
+// then returns the context's error after the repos delivered so far.
+func Scan(ctx context.Context, opts Options, onRepo func(model.Repo)) error {
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = DefaultConcurrency()
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultRepoTimeout
+	}
+	provider := opts.Provider
+	if provider == nil {
+		provider = gitstatus.ExecProvider{}
+	}
 
-		// Check if root exists
-		if _, err := os.Stat(root); os.IsNotExist(err) {
-			continue
-		}
+	found := make(chan string, workers)
+	d := newDiscovery(ctx, found, opts.Progress)
 
-		wg.Add(1)
-		go func(r string) {
-			defer wg.Done()
-			err := filepath.WalkDir(r, func(path string, d os.DirEntry, err error) error {
-				if err != nil {
-					// Skip directories we can't access
-					return nil
+	// Status stage: a fixed pool of workers inspects discovered repos
+	var deliver sync.Mutex
+	delivered := make(map[string]model.Repo)
+	var pool sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		pool.Add(1)
+		go func() {
+			defer pool.Done()
+			for repoPath := range found {
+				// Keep draining after cancellation so walkers never block
+				if ctx.Err() != nil {
+					continue
 				}
-
-				// Skip ignored directories
-				if d.IsDir() && shouldIgnore(d.Name(), ignoreSet) {
-					return filepath.SkipDir
+				parent := findParent(opts.Roots, repoPath)
+				repo, ok, reused := inspectRepo(ctx, provider, repoPath, parent, timeout, opts.Known)
+				opts.Progress.addDone()
+				if reused {
+					opts.Progress.addReused()
 				}
-
-				// Found a .git directory
-				if d.IsDir() && d.Name() == ".git" {
-					repoPath := filepath.Dir(path)
-
-					// Resolve to absolute path to get proper repo name
-					// This handles cases where path is "." or relative
-					absPath, err := filepath.Abs(repoPath)
-					if err == nil {
-						repoPath = absPath
-					}
-					repoName := filepath.Base(repoPath)
-
-					status, serr := gitstatus.Status(repoPath)
-
-					repo := model.Repo{
-						Name:   repoName,
-						Path:   repoPath,
-						Status: status,
-					}
-					if serr != nil {
-						repo.Status.ScanError = serr.Error()
-					}
-
-					mu.Lock()
-					repos = append(repos, repo)
-					mu.Unlock()
-
-					// Don't walk into .git directory
-					return filepath.SkipDir
+				if !ok || ctx.Err() != nil {
+					continue
 				}
+				deliver.Lock()
+				repo.Aliases = d.aliasesOf(repoPath)
+				delivered[repoPath] = repo
+				onRepo(repo)
+				deliver.Unlock()
+			}
+		}()
+	}
+
+	// Discovery stage: one walker per root
+	var walkers sync.WaitGroup
+	for _, root := range opts.Roots {
+		// Expand ~ and environment variables, and resolve to an absolute
+		// path to get proper repo names when the root is "." or relative
+		root.Path = absPath(expandPath(root.Path))
 
-				return nil
-			})
-			if err != nil {
+		// Check if root exists
+		if _, err := os.Stat(root.Path); os.IsNotExist(err) {
+			continue
+		}
+
+		walkers.Add(1)
+		go func(r config.Root) {
+			defer walkers.Done()
+			w := &walker{discovery: d, root: r, stopAtRepos: opts.StopAtRepos}
+			chain := []string{realPath(r.Path)}
+			if err := w.walk(r.Path, chain, 0, rootRules(r, opts), false); err != nil && ctx.Err() == nil {
 				// Log but don't fail
-				fmt.Fprintf(os.Stderr, "warning: scan error in %s: %v\n", r, err)
+				fmt.Fprintf(os.Stderr, "warning: scan error in %s: %v\n", r.Path, err)
 			}
 		}(root)
 	}
 
-	wg.Wait()
-	return repos, nil
+	// Listed repos skip the walk, and the ignore rules with it
+	walkers.Add(1)
+	go func() {
+		defer walkers.Done()
+		for _, repo := range opts.Repos {
+			repo = absPath(expandPath(repo))
+			if _, err := gitstatus.ResolveLayout(repo); err != nil {
+				continue
+			}
+			if d.send(repo) != nil {
+				return
+			}
+		}
+	}()
+
+	walkers.Wait()
+	close(found)
+	pool.Wait()
+
+	// Aliases found after their repo went out
+	if ctx.Err() == nil {
+		for repoPath, repo := range delivered {
+			if aliases := d.aliasesOf(repoPath); len(aliases) != len(repo.Aliases) {
+				repo.Aliases = aliases
+				onRepo(repo)
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// findParent returns the closest repo whose working tree contains
+// repoPath, looking no higher than the root the repo was found under, so
+// a dotfiles repo in the home directory doesn't adopt every repo
+func findParent(roots []config.Root, repoPath string) string {
+	top := ""
+	for _, r := range roots {
+		// repoPath is a real path, see Scan
+		root := realPath(absPath(expandPath(r.Path)))
+		if strings.HasPrefix(repoPath, root+string(filepath.Separator)) && len(root) > len(top) {
+			top = root
+		}
+	}
+	if top == "" {
+		return ""
+	}
+	for dir := filepath.Dir(repoPath); len(dir) >= len(top); dir = filepath.Dir(dir) {
+		if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+	}
+	return ""
 }
 
-// shouldIgnore checks if a directory name matches any ignore pattern
-func shouldIgnore(name string, ignoreSet map[string]struct{}) bool {
-	// Exact match
-	if _, ok := ignoreSet[name]; ok {
-		return true
+// inspectRepo resolves the git layout of a working tree and collects its
+// status with provider, giving it at most timeout to finish. It reports
+// ok = false if the .git entry is not a usable repo. A repo in known with
+// the same fingerprint and no scan error is returned as is, with reused
+// set. parent is the repo containing the working tree, if any.
+func inspectRepo(ctx context.Context, provider gitstatus.StatusProvider, repoPath, parent string, timeout time.Duration, known map[string]model.Repo) (repo model.Repo, ok, reused bool) {
+	layout, err := gitstatus.ResolveLayout(repoPath)
+	if err != nil {
+		return model.Repo{}, false, false
 	}
-	// Suffix match
-	for pat := range ignoreSet {
-		if strings.HasSuffix(name, pat) {
-			return true
+
+	// A linked worktree inside its main repo is grouped by MainRepo
+	// already; for anything else the parent's files decide the nesting,
+	// and they can change without touching the repo's own fingerprint
+	var nesting model.Nesting
+	if parent != "" && !(layout.Kind == model.KindWorktree && layout.MainRepo == parent) {
+		if parentLayout, err := gitstatus.ResolveLayout(parent); err == nil {
+			nesting = gitstatus.Nesting(parent, parentLayout, repoPath)
 		}
 	}
-	return false
+	if nesting == "" {
+		parent = ""
+	}
+
+	// Taken before reading status, so a change made while git runs shows
+	// up as a new fingerprint on the next scan
+	fingerprint := gitstatus.Fingerprint(repoPath, layout)
+	if prev, found := known[repoPath]; found && prev.Fingerprint == fingerprint && prev.Status.ScanError == "" {
+		prev.Parent, prev.Nesting = parent, nesting
+		return prev, true, true
+	}
+
+	repoCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var status model.RepoStatus
+	var serr error
+	if layout.Kind == model.KindBare {
+		// No working tree for the provider to read
+		status, serr = gitstatus.BareStatus(repoCtx, repoPath)
+	} else {
+		status, serr = provider.Status(repoCtx, repoPath)
+	}
+
+	repo = model.Repo{
+		Name:        filepath.Base(repoPath),
+		Path:        repoPath,
+		Kind:        layout.Kind,
+		GitDir:      layout.GitDir,
+		MainRepo:    layout.MainRepo,
+		Parent:      parent,
+		Nesting:     nesting,
+		Status:      status,
+		Fingerprint: fingerprint,
+	}
+	switch {
+	case errors.Is(serr, context.DeadlineExceeded):
+		repo.Status.TimedOut = true
+		repo.Status.ScanError = fmt.Sprintf("timed out: git did not finish within %s", timeout)
+	case errors.Is(serr, gitstatus.ErrDubiousOwnership):
+		repo.Status.Untrusted = true
+		repo.Status.ScanError = "untrusted: repository is owned by another user"
+	case serr != nil:
+		repo.Status.ScanError = serr.Error()
+	}
+	return repo, true, false
 }
 
 // expandPath expands ~ and environment variables in a path
@@ -145,6 +411,15 @@ func expandPath(path string) string {
 	return os.ExpandEnv(path)
 }
 
+// absPath resolves a path against the working directory, keeping it as is
+// if that fails
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
 // PrintJSON outputs the repos as formatted JSON
 func PrintJSON(w io.Writer, repos []model.Repo) error {
 	enc := json.NewEncoder(w)
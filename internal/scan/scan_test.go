@@ -0,0 +1,105 @@
+package scan
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bharath-code/git-scope/internal/config"
+	"github.com/Bharath-code/git-scope/internal/model"
+)
+
+// gitRun runs git in dir without the GIT_* variables of the test process
+func gitRun(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, "GIT_") {
+			cmd.Env = append(cmd.Env, kv)
+		}
+	}
+	cmd.Env = append(cmd.Env,
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// TestRescanSeesWorktreeChanges checks that a rescan with the previous
+// repos as Known does not reuse a status the worktree has moved away from
+func TestRescanSeesWorktreeChanges(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fixture relies on POSIX modification times")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	mkdirs(t, root, []string{"repo/src"}, map[string]string{
+		"repo/src/tracked.txt": "before\n",
+	})
+	gitRun(t, repo, "init", "-q")
+	gitRun(t, repo, "add", "-A")
+	gitRun(t, repo, "commit", "-q", "-m", "initial")
+	// Dated back, so the edits below get a new modification time
+	old := time.Now().Add(-time.Hour)
+	for _, path := range []string{"src/tracked.txt", "src", ""} {
+		if err := os.Chtimes(filepath.Join(repo, path), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := Options{Roots: []config.Root{{Path: root}}, StopAtRepos: true}
+	scan := func(step string, known []model.Repo) model.Repo {
+		t.Helper()
+		opts.Known = ByPath(known)
+		repos, err := Collect(context.Background(), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(repos) != 1 {
+			t.Fatalf("%s: found %d repos, want 1", step, len(repos))
+		}
+		return repos[0]
+	}
+
+	// IsDirty also counts the unpublished commit, so look at the files
+	first := scan("initial", nil)
+	if first.Status.Unstaged != 0 || first.Status.Untracked != 0 {
+		t.Fatalf("initial: status %+v, want no changed files", first.Status)
+	}
+	opts.Progress = &Progress{}
+	first = scan("unchanged", []model.Repo{first})
+	if opts.Progress.Reused() != 1 {
+		t.Fatal("unchanged: status was not reused")
+	}
+	opts.Progress = nil
+
+	// Same size, written in place: no directory or git file changes
+	if err := os.WriteFile(filepath.Join(repo, "src", "tracked.txt"), []byte("after!\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	edited := scan("edited in place", []model.Repo{first})
+	if edited.Status.Unstaged != 1 {
+		t.Errorf("edited in place: status %+v, want one unstaged file", edited.Status)
+	}
+
+	// A new file next to a tracked one, below the top level
+	if err := os.WriteFile(filepath.Join(repo, "src", "new.txt"), []byte("new\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	added := scan("new file", []model.Repo{edited})
+	if added.Status.Untracked != 1 {
+		t.Errorf("new file: status %+v, want one untracked file", added.Status)
+	}
+}
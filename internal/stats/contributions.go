@@ -1,11 +1,13 @@
 package stats
 
 import (
-	"os/exec"
+	"context"
+	"errors"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Bharath-code/git-scope/internal/gitstatus"
 	"github.com/Bharath-code/git-scope/internal/model"
 )
 
@@ -17,10 +19,14 @@ type ContributionData struct {
 	StartDate    time.Time
 	EndDate      time.Time
 	MaxDaily     int // Max commits in a single day (for scaling)
+	TimedOut     int // Repos skipped because git did not finish in time
 }
 
-// GetContributions aggregates commits from all repos for the last N weeks
-func GetContributions(repos []model.Repo, weeks int) (*ContributionData, error) {
+// GetContributions aggregates commits from all repos for the last N weeks.
+// The git call of each repo is bounded by timeout; repos that exceed it
+// are skipped and counted in TimedOut. It stops early with the context's
+// error if ctx ends.
+func GetContributions(ctx context.Context, repos []model.Repo, weeks int, timeout time.Duration) (*ContributionData, error) {
 	data := &ContributionData{
 		Days:       make(map[string]int),
 		WeeksCount: weeks,
@@ -31,7 +37,22 @@ func GetContributions(repos []model.Repo, weeks int) (*ContributionData, error)
 	sinceDate := data.StartDate.Format("2006-01-02")
 
 	for _, repo := range repos {
-		commits, err := getRepoCommits(repo.Path, sinceDate)
+		if err := ctx.Err(); err != nil {
+			return data, err
+		}
+		// git already hung on this repo during the scan
+		if repo.Status.TimedOut {
+			data.TimedOut++
+			continue
+		}
+
+		repoCtx, cancel := repoContext(ctx, timeout)
+		commits, err := getRepoCommits(repoCtx, repo.Path, sinceDate)
+		cancel()
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			data.TimedOut++
+			continue
+		}
 		if err != nil {
 			continue // Skip repos with errors
 		}
@@ -48,11 +69,18 @@ func GetContributions(repos []model.Repo, weeks int) (*ContributionData, error)
 	return data, nil
 }
 
+// repoContext bounds the work done for a single repo by timeout, or only
+// by ctx if timeout is zero
+func repoContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
 // getRepoCommits returns a list of commit dates (YYYY-MM-DD) from a repo
-func getRepoCommits(repoPath, sinceDate string) ([]string, error) {
-	cmd := exec.Command("git", "log", "--since="+sinceDate, "--format=%ad", "--date=short")
-	cmd.Dir = repoPath
-	out, err := cmd.Output()
+func getRepoCommits(ctx context.Context, repoPath, sinceDate string) ([]string, error) {
+	out, err := gitstatus.Git(ctx, repoPath, "log", "--since="+sinceDate, "--format=%ad", "--date=short")
 	if err != nil {
 		return nil, err
 	}
@@ -1,9 +1,12 @@
 package stats
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"sort"
+	"time"
 
 	"github.com/Bharath-code/git-scope/internal/model"
 )
@@ -17,6 +20,7 @@ type DiskUsageData struct {
 	MaxSize        int64
 	RepoCount      int
 	HasNodeModules bool
+	TimedOut       int // Repos skipped because measuring them took too long
 }
 
 // RepoDiskUsage holds disk usage for a single repo
@@ -28,39 +32,34 @@ type RepoDiskUsage struct {
 	TotalSize       int64 // Combined size
 }
 
-// GetDiskUsage calculates .git and node_modules folder sizes for all repos
-func GetDiskUsage(repos []model.Repo) (*DiskUsageData, error) {
+// GetDiskUsage calculates .git and node_modules folder sizes for all repos.
+// Measuring each repo is bounded by timeout; repos that exceed it are
+// skipped and counted in TimedOut. It stops early with the context's
+// error if ctx ends.
+func GetDiskUsage(ctx context.Context, repos []model.Repo, timeout time.Duration) (*DiskUsageData, error) {
 	data := &DiskUsageData{
 		Repos:     make([]RepoDiskUsage, 0, len(repos)),
 		RepoCount: len(repos),
 	}
 
 	for _, repo := range repos {
-		usage := RepoDiskUsage{
-			Name: repo.Name,
-			Path: repo.Path,
+		if err := ctx.Err(); err != nil {
+			return data, err
 		}
 
-		// Calculate .git size
-		gitPath := filepath.Join(repo.Path, ".git")
-		gitSize, err := getDirSize(gitPath)
-		if err == nil {
-			usage.GitSize = gitSize
-			data.TotalGitSize += gitSize
+		repoCtx, cancel := repoContext(ctx, timeout)
+		usage, err := getRepoDiskUsage(repoCtx, repo)
+		cancel()
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			data.TimedOut++
+			continue
 		}
 
-		// Calculate node_modules size (if exists)
-		nodePath := filepath.Join(repo.Path, "node_modules")
-		if info, err := os.Stat(nodePath); err == nil && info.IsDir() {
-			nodeSize, err := getDirSize(nodePath)
-			if err == nil {
-				usage.NodeModulesSize = nodeSize
-				data.TotalNodeSize += nodeSize
-				data.HasNodeModules = true
-			}
+		data.TotalGitSize += usage.GitSize
+		if usage.NodeModulesSize > 0 {
+			data.TotalNodeSize += usage.NodeModulesSize
+			data.HasNodeModules = true
 		}
-
-		usage.TotalSize = usage.GitSize + usage.NodeModulesSize
 		data.TotalSize += usage.TotalSize
 
 		if usage.TotalSize > data.MaxSize {
@@ -80,13 +79,43 @@ func GetDiskUsage(repos []model.Repo) (*DiskUsageData, error) {
 	return data, nil
 }
 
+// getRepoDiskUsage measures the .git and node_modules folders of a repo.
+// Folders that cannot be read count as empty; only an ended ctx is
+// returned as an error.
+func getRepoDiskUsage(ctx context.Context, repo model.Repo) (RepoDiskUsage, error) {
+	usage := RepoDiskUsage{
+		Name: repo.Name,
+		Path: repo.Path,
+	}
+
+	// Calculate .git size
+	gitPath := filepath.Join(repo.Path, ".git")
+	if gitSize, err := getDirSize(ctx, gitPath); err == nil {
+		usage.GitSize = gitSize
+	}
+
+	// Calculate node_modules size (if exists)
+	nodePath := filepath.Join(repo.Path, "node_modules")
+	if info, err := os.Stat(nodePath); err == nil && info.IsDir() {
+		if nodeSize, err := getDirSize(ctx, nodePath); err == nil {
+			usage.NodeModulesSize = nodeSize
+		}
+	}
+
+	usage.TotalSize = usage.GitSize + usage.NodeModulesSize
+	return usage, ctx.Err()
+}
+
 // getDirSize calculates the total size of a directory
-func getDirSize(path string) (int64, error) {
+func getDirSize(ctx context.Context, path string) (int64, error) {
 	var size int64
 	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		if !info.IsDir() {
 			size += info.Size()
 		}
@@ -1,18 +1,22 @@
 package stats
 
 import (
-	"os/exec"
+	"context"
+	"errors"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/Bharath-code/git-scope/internal/gitstatus"
 	"github.com/Bharath-code/git-scope/internal/model"
+	"github.com/mattn/go-runewidth"
 )
 
 // TimelineData holds timeline information
 type TimelineData struct {
 	Entries    []TimelineEntry
 	TotalRepos int
+	TimedOut   int // Repos whose commit message git did not read in time
 }
 
 // TimelineEntry represents a repo activity entry
@@ -26,8 +30,11 @@ type TimelineEntry struct {
 	DayLabel   string // "Today", "Yesterday", "2 days ago", etc.
 }
 
-// GetTimeline gets recent activity timeline sorted by last commit
-func GetTimeline(repos []model.Repo) (*TimelineData, error) {
+// GetTimeline gets recent activity timeline sorted by last commit.
+// Reading the commit message of each repo is bounded by timeout; repos
+// that exceed it are listed without one and counted in TimedOut. It stops
+// early with the context's error if ctx ends.
+func GetTimeline(ctx context.Context, repos []model.Repo, timeout time.Duration) (*TimelineData, error) {
 	data := &TimelineData{
 		Entries:    make([]TimelineEntry, 0, len(repos)),
 		TotalRepos: len(repos),
@@ -37,14 +44,29 @@ func GetTimeline(repos []model.Repo) (*TimelineData, error) {
 	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 
 	for _, repo := range repos {
+		if err := ctx.Err(); err != nil {
+			return data, err
+		}
+
 		// Get last commit info
 		lastCommit := repo.Status.LastCommit
 		if lastCommit.IsZero() {
 			continue
 		}
 
-		// Get commit message
-		message := getLastCommitMessage(repo.Path)
+		// Get commit message, unless git already hung on this repo
+		message := ""
+		if repo.Status.TimedOut {
+			data.TimedOut++
+		} else {
+			repoCtx, cancel := repoContext(ctx, timeout)
+			var err error
+			message, err = getLastCommitMessage(repoCtx, repo.Path)
+			cancel()
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				data.TimedOut++
+			}
+		}
 
 		entry := TimelineEntry{
 			Name:       repo.Name,
@@ -52,7 +74,7 @@ func GetTimeline(repos []model.Repo) (*TimelineData, error) {
 			Branch:     repo.Status.Branch,
 			LastCommit: lastCommit,
 			Message:    message,
-			TimeAgo:    formatTimeAgo(lastCommit, now),
+			TimeAgo:    FormatTimeAgo(lastCommit, now),
 			DayLabel:   formatDayLabel(lastCommit, today),
 		}
 
@@ -68,22 +90,17 @@ func GetTimeline(repos []model.Repo) (*TimelineData, error) {
 }
 
 // getLastCommitMessage gets the last commit message for a repo
-func getLastCommitMessage(repoPath string) string {
-	cmd := exec.Command("git", "log", "-1", "--format=%s")
-	cmd.Dir = repoPath
-	out, err := cmd.Output()
+func getLastCommitMessage(ctx context.Context, repoPath string) (string, error) {
+	out, err := gitstatus.Git(ctx, repoPath, "log", "-1", "--format=%s")
 	if err != nil {
-		return ""
-	}
-	msg := strings.TrimSpace(string(out))
-	if len(msg) > 50 {
-		msg = msg[:47] + "..."
+		return "", err
 	}
-	return msg
+	msg := runewidth.Truncate(strings.TrimSpace(string(out)), 50, "...")
+	return msg, nil
 }
 
-// formatTimeAgo formats a time as "2 hours ago", "3 days ago", etc.
-func formatTimeAgo(t time.Time, now time.Time) string {
+// FormatTimeAgo formats a time as "2 hours ago", "3 days ago", etc.
+func FormatTimeAgo(t time.Time, now time.Time) string {
 	diff := now.Sub(t)
 
 	switch {
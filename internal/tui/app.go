@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"time"
 
 	"github.com/Bharath-code/git-scope/internal/cache"
@@ -21,48 +22,133 @@ func Run(cfg *config.Config) error {
 	return err
 }
 
-// scanReposCmd is a command that scans for repositories
-// If forceRefresh is true, bypass cache and scan fresh
-func scanReposCmd(cfg *config.Config, forceRefresh bool) tea.Cmd {
+// scanReposCmd is a command that scans for repositories. Unless
+// forceRefresh is set, a fresh enough cache of the same roots and scan
+// settings is used as is. Otherwise the roots are scanned and repos in
+// known whose fingerprint is unchanged keep their status; with known nil
+// and forceRefresh unset, the cached repos serve as known.
+func scanReposCmd(cfg *config.Config, forceRefresh bool, known map[string]model.Repo, id int) tea.Cmd {
 	return func() tea.Msg {
 		cacheStore := cache.NewFileStore()
 
 		// Try to load from cache first (unless forcing refresh)
 		if !forceRefresh {
 			cached, err := cacheStore.Load()
-			if err == nil && cacheStore.IsValid(cacheMaxAge) && cacheStore.IsSameRoots(cfg.Roots) {
-				return scanCompleteMsg{
-					repos:     cached.Repos,
-					fromCache: true,
+			if err == nil && cacheStore.IsSameScan(cfg.RootPaths(), cfg.ScanKey()) {
+				if cacheStore.IsValid(cacheMaxAge) {
+					return scanCompleteMsg{
+						repos:     cached.Repos,
+						fromCache: true,
+					}
+				}
+				if known == nil {
+					known = scan.ByPath(cached.Repos)
 				}
 			}
 		}
 
-		// Scan fresh
-		repos, err := scan.ScanRoots(cfg.Roots, cfg.Ignore)
-		if err != nil {
-			return scanErrorMsg{err: err}
-		}
-
-		// Save to cache
-		_ = cacheStore.Save(repos, cfg.Roots)
-
-		return scanCompleteMsg{
-			repos:     repos,
-			fromCache: false,
-		}
+		// Scan, saving to cache once every repo is in
+		opts := scan.OptionsFor(cfg)
+		opts.Known = known
+		stream := startScan(id, opts, "", func(repos []model.Repo) {
+			_ = cacheStore.Save(repos, cfg.RootPaths(), cfg.ScanKey())
+		})
+		return scanStartedMsg{stream: stream}
 	}
 }
 
-// scanCompleteMsg is sent when scanning is complete
+// scanCompleteMsg is sent when repos are loaded from cache
 type scanCompleteMsg struct {
 	repos     []model.Repo
 	fromCache bool
 }
 
-// scanErrorMsg is sent when scanning fails
-type scanErrorMsg struct {
-	err error
+// scanStream is a scan running in the background that delivers repos as
+// soon as their status is known
+type scanStream struct {
+	id        int
+	repos     chan model.Repo
+	progress  *scan.Progress
+	started   time.Time
+	workspace string // Path of a switched-to workspace, empty for the config roots
+	err       error  // Set before repos is closed
+	cancel    context.CancelFunc
+}
+
+// scanBatchSize caps the number of repos delivered in one message
+const scanBatchSize = 64
+
+// startScan runs a scan in the background. onDone receives every repo
+// found before the stream is closed, unless the scan was cancelled.
+func startScan(id int, opts scan.Options, workspace string, onDone func([]model.Repo)) *scanStream {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &scanStream{
+		id:        id,
+		repos:     make(chan model.Repo, scanBatchSize),
+		progress:  &scan.Progress{},
+		started:   time.Now(),
+		workspace: workspace,
+		cancel:    cancel,
+	}
+	opts.Progress = s.progress
+
+	go func() {
+		defer cancel()
+		var all []model.Repo
+		index := make(map[string]int)
+		s.err = scan.Scan(ctx, opts, func(r model.Repo) {
+			all = scan.AddRepo(all, index, r)
+			s.repos <- r
+		})
+		if s.err == nil && onDone != nil {
+			onDone(all)
+		}
+		close(s.repos)
+	}()
+
+	return s
+}
+
+// scanStartedMsg is sent when a background scan has started
+type scanStartedMsg struct {
+	stream *scanStream
+}
+
+// reposFoundMsg delivers the repos a background scan found since the last one
+type reposFoundMsg struct {
+	stream *scanStream
+	repos  []model.Repo
+}
+
+// scanFinishedMsg is sent when a background scan has delivered every repo
+type scanFinishedMsg struct {
+	stream *scanStream
+}
+
+// waitForReposCmd waits for the next repos of a background scan. It
+// blocks for the first one, then takes whatever else is already queued
+// so a fast scan does not redraw the table once per repo.
+func waitForReposCmd(s *scanStream) tea.Cmd {
+	return func() tea.Msg {
+		r, ok := <-s.repos
+		if !ok {
+			return scanFinishedMsg{stream: s}
+		}
+
+		batch := []model.Repo{r}
+		for len(batch) < scanBatchSize {
+			select {
+			case r, ok := <-s.repos:
+				if !ok {
+					return reposFoundMsg{stream: s, repos: batch}
+				}
+				batch = append(batch, r)
+			default:
+				return reposFoundMsg{stream: s, repos: batch}
+			}
+		}
+		return reposFoundMsg{stream: s, repos: batch}
+	}
 }
 
 // openEditorMsg is sent to trigger opening an editor
@@ -0,0 +1,323 @@
+package tui
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Bharath-code/git-scope/internal/gitstatus"
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Diff preview styling
+var (
+	diffAddStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#22c55e"))
+	diffDelStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#ef4444"))
+	diffHunkStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#22d3ee"))
+	diffHeaderStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF")).Bold(true)
+	diffMetaStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#6B7280"))
+
+	diffAddBackground = lipgloss.Color("#14321f")
+	diffDelBackground = lipgloss.Color("#3b1219")
+
+	diffBoxStyle = lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("#7C3AED")).
+			Padding(0, 1)
+)
+
+// diffLoadedMsg is sent when the diff of a single file is loaded
+type diffLoadedMsg struct {
+	title   string
+	path    string
+	content string
+	err     error
+}
+
+// loadDiffCmd loads the read-only diff preview of a changed file
+func loadDiffCmd(repoPath, repoName string, f gitstatus.FileChange, timeout time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		content, err := gitstatus.FileDiff(ctx, repoPath, f)
+		return diffLoadedMsg{
+			title:   repoName + " · " + displayPath(f),
+			path:    f.Path,
+			content: content,
+			err:     err,
+		}
+	}
+}
+
+// handleDetailFocusMode handles key events while the file list of the
+// detail pane has focus
+func (m Model) handleDetailFocusMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var files []gitstatus.FileChange
+	if m.detailData != nil {
+		files = m.detailData.Files
+	}
+
+	switch msg.String() {
+	case "tab", "esc":
+		m.detailFocus = false
+		m.statusMsg = ""
+		return m, nil
+
+	case "up", "k":
+		if m.detailCursor > 0 {
+			m.detailCursor--
+		}
+		return m, nil
+
+	case "down", "j":
+		if m.detailCursor < len(files)-1 {
+			m.detailCursor++
+		}
+		return m, nil
+
+	case "enter":
+		repo := m.GetSelectedRepo()
+		if repo == nil || m.detailCursor >= len(files) {
+			return m, nil
+		}
+		f := files[m.detailCursor]
+		m.statusMsg = "Loading diff for " + f.Path + "..."
+		return m, loadDiffCmd(repo.Path, repo.Name, f, m.repoTimeout())
+
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+// handleDiffMode handles key events while the diff preview is open
+func (m Model) handleDiffMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "backspace":
+		m.state = StateReady
+		m.statusMsg = ""
+		return m, nil
+
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+
+	var cmd tea.Cmd
+	m.diffView, cmd = m.diffView.Update(msg)
+	return m, cmd
+}
+
+// resizeDiffView fits the diff viewport to the window
+func (m *Model) resizeDiffView() {
+	w := m.width - 8
+	h := m.height - 8
+	if w < 20 {
+		w = 20
+	}
+	if h < 3 {
+		h = 3
+	}
+	m.diffView.Width = w
+	m.diffView.Height = h
+}
+
+// renderDiffView renders the scrollable diff preview
+func (m Model) renderDiffView() string {
+	var b strings.Builder
+
+	b.WriteString(compactLogo())
+	b.WriteString("  ")
+	b.WriteString(panelSubtitleStyle.Render(m.diffTitle))
+	b.WriteString("\n")
+
+	b.WriteString(diffBoxStyle.Render(m.diffView.View()))
+	b.WriteString("\n")
+
+	items := []string{
+		keyBinding("↑↓", "scroll"),
+		keyBinding("pgup/pgdn", "page"),
+		keyBinding("esc", "back"),
+	}
+	b.WriteString(keyBindingsBarStyle.Render(strings.Join(items, keyBindingSepStyle.Render(" │ "))))
+
+	return b.String()
+}
+
+// diffSyntaxTheme is the chroma style the code in a diff preview is
+// highlighted with
+const diffSyntaxTheme = "monokai"
+
+// colorizeDiff colors unified diff output: file headers bold, hunk headers
+// cyan, and the code in each hunk highlighted for the language of path,
+// with added lines marked green and removed lines red. Files no lexer
+// knows get their added and removed lines colored as a whole.
+func colorizeDiff(diff, path string) string {
+	if strings.TrimSpace(diff) == "" {
+		return diffMetaStyle.Render("No changes to show.")
+	}
+
+	lexer := lexers.Match(filepath.Base(path))
+	if lexer != nil {
+		lexer = chroma.Coalesce(lexer)
+	}
+
+	lines := strings.Split(strings.TrimRight(diff, "\n"), "\n")
+	for i := range lines {
+		// Tabs would throw off the viewport width calculation
+		lines[i] = strings.ReplaceAll(lines[i], "\t", "    ")
+	}
+
+	out := make([]string, 0, len(lines))
+	inHunk := false
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		// Highlight a hunk body at once, so constructs spanning lines
+		// like block comments and strings keep their color
+		if inHunk && isHunkLine(line) {
+			end := i
+			for end < len(lines) && isHunkLine(lines[end]) {
+				end++
+			}
+			out = append(out, highlightHunk(lexer, lines[i:end])...)
+			i = end - 1
+			continue
+		}
+
+		inHunk = false
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+			out = append(out, diffHunkStyle.Render(line))
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			out = append(out, diffHeaderStyle.Render(line))
+		default:
+			out = append(out, diffMetaStyle.Render(line))
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// isHunkLine reports whether a line belongs to the body of a hunk
+func isHunkLine(line string) bool {
+	return line == "" || strings.ContainsAny(line[:1], " +-\\")
+}
+
+// highlightHunk colors the lines of a hunk body. The code after the
+// +/- markers is tokenized as one text and split back into lines.
+func highlightHunk(lexer chroma.Lexer, lines []string) []string {
+	out := make([]string, len(lines))
+	if lexer == nil {
+		for i, line := range lines {
+			out[i] = plainHunkLine(line)
+		}
+		return out
+	}
+
+	var code []string
+	for _, line := range lines {
+		if line != "" && line[0] != '\\' {
+			code = append(code, line[1:])
+		} else if line == "" {
+			code = append(code, "")
+		}
+	}
+	it, err := lexer.Tokenise(nil, strings.Join(code, "\n"))
+	if err != nil {
+		for i, line := range lines {
+			out[i] = plainHunkLine(line)
+		}
+		return out
+	}
+	highlighted := splitTokens(it.Tokens(), len(code))
+
+	style := styles.Get(diffSyntaxTheme)
+	n := 0
+	for i, line := range lines {
+		var marker byte
+		if line != "" {
+			marker = line[0]
+		}
+		if marker == '\\' {
+			out[i] = diffMetaStyle.Render(line)
+			continue
+		}
+
+		var b strings.Builder
+		switch marker {
+		case '+':
+			b.WriteString(diffAddStyle.Bold(true).Render("+"))
+		case '-':
+			b.WriteString(diffDelStyle.Bold(true).Render("-"))
+		case ' ':
+			b.WriteString(" ")
+		}
+		for _, tok := range highlighted[n] {
+			b.WriteString(tokenStyle(style, tok.Type, marker).Render(tok.Value))
+		}
+		n++
+		out[i] = b.String()
+	}
+	return out
+}
+
+// splitTokens groups tokens by the line they are on, splitting the ones
+// that span a line break
+func splitTokens(tokens []chroma.Token, lines int) [][]chroma.Token {
+	out := make([][]chroma.Token, lines)
+	n := 0
+	for _, tok := range tokens {
+		parts := strings.Split(tok.Value, "\n")
+		for j, part := range parts {
+			if j > 0 {
+				n++
+			}
+			if n >= lines {
+				return out
+			}
+			if part != "" {
+				out[n] = append(out[n], chroma.Token{Type: tok.Type, Value: part})
+			}
+		}
+	}
+	return out
+}
+
+// tokenStyle styles a token in the colors of the chroma style, with a
+// green or red background on added and removed lines
+func tokenStyle(style *chroma.Style, t chroma.TokenType, marker byte) lipgloss.Style {
+	s := lipgloss.NewStyle()
+	entry := style.Get(t)
+	if entry.Colour.IsSet() {
+		s = s.Foreground(lipgloss.Color(entry.Colour.String()))
+	}
+	if entry.Bold == chroma.Yes {
+		s = s.Bold(true)
+	}
+	switch marker {
+	case '+':
+		s = s.Background(diffAddBackground)
+	case '-':
+		s = s.Background(diffDelBackground)
+	}
+	return s
+}
+
+// plainHunkLine colors a hunk line without syntax highlighting
+func plainHunkLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "+"):
+		return diffAddStyle.Render(line)
+	case strings.HasPrefix(line, "-"):
+		return diffDelStyle.Render(line)
+	case strings.HasPrefix(line, "\\"):
+		return diffMetaStyle.Render(line)
+	}
+	return line
+}
@@ -2,17 +2,24 @@ package tui
 
 import (
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/Bharath-code/git-scope/internal/config"
+	"github.com/Bharath-code/git-scope/internal/gitstatus"
 	"github.com/Bharath-code/git-scope/internal/model"
+	"github.com/Bharath-code/git-scope/internal/scan"
 	"github.com/Bharath-code/git-scope/internal/stats"
+	"github.com/Bharath-code/git-scope/internal/watch"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 )
 
 // State represents the current UI state
@@ -24,6 +31,7 @@ const (
 	StateError
 	StateSearching
 	StateWorkspaceSwitch
+	StateDiff
 )
 
 // SortMode represents different sorting options
@@ -34,6 +42,9 @@ const (
 	SortByName
 	SortByBranch
 	SortByLastCommit
+	SortByStash
+
+	sortModeCount // number of sort modes, used for cycling
 )
 
 // FilterMode represents different filter options
@@ -43,6 +54,13 @@ const (
 	FilterAll FilterMode = iota
 	FilterDirty
 	FilterClean
+	FilterConflicts
+	FilterInProgress
+	FilterUnpublished
+	FilterErrors
+	FilterBare
+
+	filterModeCount // number of filter modes, used for cycling
 )
 
 // Model is the Bubbletea model for the TUI
@@ -67,6 +85,14 @@ type Model struct {
 	grassData    *stats.ContributionData
 	diskData     *stats.DiskUsageData
 	timelineData *stats.TimelineData
+	detailData   *gitstatus.Detail
+	detailErr    error  // Why the detail data could not be loaded
+	detailPath   string // Repo the detail data belongs to (or is loading for)
+	detailFocus  bool   // File list of the detail pane has keyboard focus
+	detailCursor int
+	// Diff preview state
+	diffView  viewport.Model
+	diffTitle string
 	// Workspace switch state
 	workspaceInput  textinput.Model
 	workspaceError  string
@@ -77,12 +103,18 @@ type Model struct {
 	// Pagination state
 	currentPage int
 	pageSize    int
+	// Background scan state
+	scanSeq    int         // ID of the most recently started scan
+	activeScan *scanStream // Scan still streaming repos, nil when idle
+	// Auto-refresh state
+	watcher     *watch.Watcher       // Running while auto-refresh is on
+	highlighted map[string]time.Time // Rows whose status just changed, until when
 }
 
 // NewModel creates a new TUI model
 func NewModel(cfg *config.Config) Model {
 	columns := []table.Column{
-		{Title: "Status", Width: 8},
+		{Title: "Status", Width: 12},
 		{Title: "Repository", Width: 18},
 		{Title: "Branch", Width: 14},
 		{Title: "Staged", Width: 6},
@@ -90,6 +122,8 @@ func NewModel(cfg *config.Config) Model {
 		{Title: "Untracked", Width: 9},
 		{Title: "Ahead", Width: 7},
 		{Title: "Behind", Width: 7},
+		{Title: "Stash", Width: 5},
+		{Title: "Subs", Width: 6},
 		{Title: "Last Commit", Width: 14},
 	}
 
@@ -139,23 +173,57 @@ func NewModel(cfg *config.Config) Model {
 	sp.Spinner = spinner.Dot
 	sp.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#7C3AED"))
 
+	// Auto-refresh starts watching once the first repos are in
+	var watcher *watch.Watcher
+	if cfg.Watch {
+		watcher = watch.New(watch.Options{})
+	}
+
 	return Model{
 		cfg:            cfg,
 		table:          t,
 		textInput:      ti,
 		workspaceInput: wi,
 		spinner:        sp,
+		diffView:       viewport.New(80, 20),
 		state:          StateLoading,
 		sortMode:       SortByDirty,
 		filterMode:     FilterAll,
 		currentPage:    0,
 		pageSize:       cfg.PageSize,
+		watcher:        watcher,
+		highlighted:    make(map[string]time.Time),
 	}
 }
 
 // Init initializes the model
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(m.spinner.Tick, scanReposCmd(m.cfg, false))
+	cmds := []tea.Cmd{m.spinner.Tick, scanReposCmd(m.cfg, false, nil, m.scanSeq)}
+	if m.watcher != nil {
+		cmds = append(cmds, waitForChangesCmd(m.watcher))
+	}
+	return tea.Batch(cmds...)
+}
+
+// watchRepos points the watcher, if auto-refresh is on, at the repos
+// currently listed
+func (m Model) watchRepos() {
+	if m.watcher == nil {
+		return
+	}
+	paths := make([]string, len(m.repos))
+	for i, r := range m.repos {
+		paths[i] = r.Path
+	}
+	m.watcher.SetRepos(paths)
+}
+
+// repoTimeout returns how long git may take on a single repo
+func (m Model) repoTimeout() time.Duration {
+	if m.cfg.RepoTimeout > 0 {
+		return m.cfg.RepoTimeout
+	}
+	return scan.DefaultRepoTimeout
 }
 
 // GetSelectedRepo returns the currently selected repo
@@ -187,7 +255,27 @@ func (m *Model) applyFilter() {
 				continue
 			}
 		case FilterClean:
-			if r.Status.IsDirty {
+			if r.Status.IsDirty || r.Status.ScanError != "" || r.Status.Bare != nil {
+				continue
+			}
+		case FilterConflicts:
+			if r.Status.Conflicts == 0 {
+				continue
+			}
+		case FilterInProgress:
+			if r.Status.Operation == "" {
+				continue
+			}
+		case FilterUnpublished:
+			if !scan.HasUnpublishedWork(r.Status) {
+				continue
+			}
+		case FilterErrors:
+			if r.Status.ScanError == "" {
+				continue
+			}
+		case FilterBare:
+			if r.Kind != model.KindBare {
 				continue
 			}
 		}
@@ -234,14 +322,123 @@ func (m *Model) sortRepos() {
 		sort.Slice(m.sortedRepos, func(i, j int) bool {
 			return m.sortedRepos[i].Status.LastCommit.After(m.sortedRepos[j].Status.LastCommit)
 		})
+	case SortByStash:
+		sort.Slice(m.sortedRepos, func(i, j int) bool {
+			if m.sortedRepos[i].Status.Stashes != m.sortedRepos[j].Status.Stashes {
+				return m.sortedRepos[i].Status.Stashes > m.sortedRepos[j].Status.Stashes
+			}
+			return m.sortedRepos[i].Name < m.sortedRepos[j].Name
+		})
+	}
+
+	m.sortedRepos = groupWorktrees(m.sortedRepos)
+}
+
+// groupWorktrees moves linked worktrees directly below their main repo,
+// keeping the current sort order within each group. Worktrees whose main
+// repo is not in the list stay where they are.
+func groupWorktrees(repos []model.Repo) []model.Repo {
+	present := make(map[string]bool, len(repos))
+	for _, r := range repos {
+		if r.Kind != model.KindWorktree {
+			present[r.Path] = true
+		}
+	}
+
+	children := make(map[string][]model.Repo)
+	for _, r := range repos {
+		if r.Kind == model.KindWorktree && present[r.MainRepo] {
+			children[r.MainRepo] = append(children[r.MainRepo], r)
+		}
+	}
+	if len(children) == 0 {
+		return repos
 	}
+
+	grouped := make([]model.Repo, 0, len(repos))
+	for _, r := range repos {
+		if r.Kind == model.KindWorktree && present[r.MainRepo] {
+			continue
+		}
+		grouped = append(grouped, r)
+		grouped = append(grouped, children[r.Path]...)
+	}
+	return grouped
 }
 
 // updateTable refreshes the table with current filtered and sorted repos
 func (m *Model) updateTable() {
 	m.applyFilter()
 	m.sortRepos()
-	m.table.SetRows(reposToRows(m.getCurrentPageRepos()))
+	m.table.SetRows(m.pageRows())
+}
+
+// updateTableKeepSelection refreshes the table while keeping the cursor on
+// the repo it was on, following it to another row or page if it moved
+func (m *Model) updateTableKeepSelection() {
+	var selected string
+	if repo := m.GetSelectedRepo(); repo != nil {
+		selected = repo.Path
+	}
+
+	m.updateTable()
+	if selected == "" {
+		return
+	}
+
+	for i, r := range m.sortedRepos {
+		if r.Path == selected {
+			m.currentPage = i / m.pageSize
+			m.table.SetRows(m.pageRows())
+			m.table.SetCursor(i % m.pageSize)
+			return
+		}
+	}
+}
+
+// patchRepo replaces a repo in place after a refresh. The table is not
+// re-sorted or re-filtered, so the page and cursor stay where they are.
+func (m *Model) patchRepo(repo model.Repo) {
+	// A refresh doesn't walk, so only the scan knows the aliases
+	if old := m.repoByPath(repo.Path); old != nil && repo.Aliases == nil {
+		repo.Aliases = old.Aliases
+	}
+	for _, list := range [][]model.Repo{m.repos, m.filteredRepos, m.sortedRepos} {
+		for i := range list {
+			if list[i].Path == repo.Path {
+				list[i] = repo
+			}
+		}
+	}
+	m.table.SetRows(m.pageRows())
+}
+
+// repoByPath returns the listed repo with a path, or nil
+func (m Model) repoByPath(path string) *model.Repo {
+	for i := range m.repos {
+		if m.repos[i].Path == path {
+			return &m.repos[i]
+		}
+	}
+	return nil
+}
+
+// rowChanged reports whether a repo would be shown differently
+func rowChanged(old, repo model.Repo) bool {
+	return !reflect.DeepEqual(reposToRows([]model.Repo{old}, nil), reposToRows([]model.Repo{repo}, nil))
+}
+
+// pageRows returns the table rows of the current page, marking repos
+// whose status just changed
+func (m Model) pageRows() []table.Row {
+	now := time.Now()
+	changed := make(map[string]bool)
+	for path, until := range m.highlighted {
+		if now.Before(until) {
+			changed[path] = true
+		}
+	}
+	return reposToRows(m.getCurrentPageRepos(), changed)
 }
 
 // getTotalPages returns the total number of pages
@@ -298,6 +495,8 @@ func (m Model) GetSortModeName() string {
 		return "Branch"
 	case SortByLastCommit:
 		return "Recent"
+	case SortByStash:
+		return "Stashes"
 	}
 	return "Unknown"
 }
@@ -311,12 +510,23 @@ func (m Model) GetFilterModeName() string {
 		return "Dirty Only"
 	case FilterClean:
 		return "Clean Only"
+	case FilterConflicts:
+		return "Conflicts"
+	case FilterInProgress:
+		return "In Progress"
+	case FilterUnpublished:
+		return "Unpublished"
+	case FilterErrors:
+		return "Errors"
+	case FilterBare:
+		return "Bare Repos"
 	}
 	return "All"
 }
 
-// reposToRows converts repos to table rows with status indicators
-func reposToRows(repos []model.Repo) []table.Row {
+// reposToRows converts repos to table rows with status indicators. Repos
+// in changed get a marker in front of their name.
+func reposToRows(repos []model.Repo, changed map[string]bool) []table.Row {
 	rows := make([]table.Row, 0, len(repos))
 	for _, r := range repos {
 		lastCommit := "N/A"
@@ -324,33 +534,107 @@ func reposToRows(repos []model.Repo) []table.Row {
 			lastCommit = r.Status.LastCommit.Format("Jan 02 15:04")
 		}
 
-		// Status indicator with text
-		status := "✓ Clean"
-		if r.Status.IsDirty {
-			status = "● Dirty"
+		status := statusLabel(r.Status)
+
+		// Linked worktrees are grouped under their main repo
+		name := r.Name
+		if changed[r.Path] {
+			name = "✦ " + name
+		}
+		if r.Kind == model.KindWorktree {
+			name = "↳ " + name
+		}
+
+		// Bare repos have no working tree to count changes in
+		staged := formatNumber(r.Status.Staged)
+		unstaged := formatNumber(r.Status.Unstaged)
+		untracked := formatNumber(r.Status.Untracked)
+		if r.Status.Bare != nil {
+			staged, unstaged, untracked = "—", "—", "—"
 		}
 
 		rows = append(rows, table.Row{
 			status,
-			truncateString(r.Name, 18),
+			truncateString(name, 18),
 			truncateString(r.Status.Branch, 14),
-			formatNumber(r.Status.Staged),
-			formatNumber(r.Status.Unstaged),
-			formatNumber(r.Status.Untracked),
-			formatNumber(r.Status.Ahead),
+			staged,
+			unstaged,
+			untracked,
+			formatAhead(r.Status),
 			formatNumber(r.Status.Behind),
+			formatNumber(r.Status.Stashes),
+			formatSubmodules(r.Status.Submodules),
 			lastCommit,
 		})
 	}
 	return rows
 }
 
-// truncateString shortens a string with ellipsis
-func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
+// statusLabel returns the status indicator with text for a repo row.
+// Untrusted, timed out and otherwise failed repos come first since the
+// rest of their status is missing or incomplete.
+// Conflicts and in-progress operations take priority over plain dirtiness
+// since they usually block any further work in the repo.
+func statusLabel(s model.RepoStatus) string {
+	switch {
+	case s.Untrusted:
+		return "⛔ Untrusted"
+	case s.TimedOut:
+		return "⏱ Timeout"
+	case s.ScanError != "":
+		return "✗ Error"
+	case s.Bare != nil && s.Bare.Mirror:
+		return "🪞 Mirror"
+	case s.Bare != nil:
+		return "🗄 Bare"
+	case s.Conflicts > 0:
+		return "⚠ Conflict"
+	case s.Operation != "":
+		return operationLabel(s.Operation)
+	case s.Staged == 0 && s.Unstaged == 0 && s.Untracked == 0 && s.Unpublished > 0:
+		return "⇡ Unpushed"
+	case s.IsDirty:
+		return "● Dirty"
+	default:
+		return "✓ Clean"
+	}
+}
+
+// operationLabel returns the display label for an in-progress operation
+func operationLabel(op model.Operation) string {
+	switch op {
+	case model.OpRebase:
+		return "⟳ Rebasing"
+	case model.OpAm:
+		return "⟳ Applying"
+	case model.OpMerge:
+		return "⟳ Merging"
+	case model.OpCherryPick:
+		return "⟳ Picking"
+	case model.OpRevert:
+		return "⟳ Reverting"
+	case model.OpBisect:
+		return "⟳ Bisecting"
+	}
+	return "⟳ " + string(op)
+}
+
+// formatAhead formats the ahead count. Branches without a usable upstream
+// show their unpublished commits instead, marked with ⇡.
+func formatAhead(s model.RepoStatus) string {
+	if s.NoUpstream || s.UpstreamGone || s.Upstream == "" {
+		if s.Unpublished > 0 {
+			return fmt.Sprintf("⇡%d", s.Unpublished)
+		}
+		return "—"
 	}
-	return s[:maxLen-1] + "…"
+	return formatNumber(s.Ahead)
+}
+
+// truncateString shortens a string with ellipsis to at most maxLen
+// terminal cells, never cutting a character in half
+func truncateString(s string, maxLen int) string {
+	return runewidth.Truncate(s, maxLen, "…")
 }
 
 // formatNumber formats a number for display
@@ -361,6 +645,25 @@ func formatNumber(n int) string {
 	return fmt.Sprintf("%d", n)
 }
 
+// formatSubmodules summarizes submodule state for the table, flagging
+// submodules that are out of sync or dirty
+func formatSubmodules(subs []model.Submodule) string {
+	if len(subs) == 0 {
+		return "—"
+	}
+
+	attention := 0
+	for _, s := range subs {
+		if s.OutOfSync || s.Dirty {
+			attention++
+		}
+	}
+	if attention > 0 {
+		return fmt.Sprintf("⚠ %d/%d", attention, len(subs))
+	}
+	return fmt.Sprintf("%d", len(subs))
+}
+
 // resizeTable calculates and sets the correct table height based on UI state
 func (m *Model) resizeTable() {
 	usedHeight := 12 // Header + Stats + Legend + Help + Padding
@@ -2,11 +2,15 @@ package tui
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/Bharath-code/git-scope/internal/gitstatus"
+	"github.com/Bharath-code/git-scope/internal/model"
 	"github.com/Bharath-code/git-scope/internal/stats"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 )
 
 // PanelType represents which panel is currently active
@@ -17,6 +21,9 @@ const (
 	PanelGrass
 	PanelDisk
 	PanelTimeline
+	PanelSubmodules
+	PanelUnpushed
+	PanelDetail
 )
 
 // Heatmap color palette (GitHub-style green gradient)
@@ -162,10 +169,20 @@ func renderGrassPanel(data *stats.ContributionData, width, height int) string {
 	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFFFFF")).Render(
 		fmt.Sprintf("%d", data.WeeksCount)))
 	b.WriteString(panelMutedStyle.Render(" weeks"))
+	b.WriteString(timedOutNote(data.TimedOut))
 
 	return b.String()
 }
 
+// timedOutNote tells how many repos a stats panel is missing because git
+// did not finish within the per-repo timeout
+func timedOutNote(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return "\n" + panelMutedStyle.Render(fmt.Sprintf("⏱ %d repos timed out", n))
+}
+
 // getHeatmapBlock returns a colored block for the heatmap based on intensity level
 func getHeatmapBlock(level int) string {
 	block := "██" // Full block character (2 chars wide for visibility)
@@ -195,6 +212,12 @@ func getPanelHelp(panel PanelType) string {
 		return helpItem("d", "close") + " • " + helpItem("esc", "close")
 	case PanelTimeline:
 		return helpItem("t", "close") + " • " + helpItem("esc", "close")
+	case PanelSubmodules:
+		return helpItem("m", "close") + " • " + helpItem("esc", "close")
+	case PanelUnpushed:
+		return helpItem("p", "close") + " • " + helpItem("esc", "close")
+	case PanelDetail:
+		return helpItem("i", "close") + " • " + helpItem("esc", "close")
 	default:
 		return ""
 	}
@@ -262,11 +285,7 @@ func renderDiskPanel(data *stats.DiskUsageData, width, height int) string {
 		}
 
 		// Truncate name
-		name := repo.Name
-		if len(name) > 10 {
-			name = name[:9] + "…"
-		}
-		name = fmt.Sprintf("%-10s", name)
+		name := runewidth.FillRight(truncateString(repo.Name, 10), 10)
 
 		// Calculate bar lengths
 		gitBarLen := diskBarLen(repo.GitSize, data.MaxSize, barWidth)
@@ -293,6 +312,7 @@ func renderDiskPanel(data *stats.DiskUsageData, width, height int) string {
 		b.WriteString(diskBarNode.Render("█"))
 		b.WriteString(panelMutedStyle.Render(" node_modules"))
 	}
+	b.WriteString(timedOutNote(data.TimedOut))
 
 	return b.String()
 }
@@ -365,6 +385,7 @@ func renderTimelinePanel(data *stats.TimelineData, width, height int) string {
 
 	if len(data.Entries) == 0 {
 		b.WriteString(panelMutedStyle.Render("No recent commits found."))
+		b.WriteString(timedOutNote(data.TimedOut))
 		return b.String()
 	}
 
@@ -399,19 +420,13 @@ func renderTimelinePanel(data *stats.TimelineData, width, height int) string {
 		}
 
 		// Entry
-		name := entry.Name
-		if len(name) > 15 {
-			name = name[:14] + "…"
-		}
+		name := truncateString(entry.Name, 15)
 
 		b.WriteString("  ")
 		b.WriteString(timelineRepoStyle.Render(name))
 		b.WriteString(" ")
 
-		branch := entry.Branch
-		if len(branch) > 10 {
-			branch = branch[:9] + "…"
-		}
+		branch := truncateString(entry.Branch, 10)
 		b.WriteString(timelineBranchStyle.Render("(" + branch + ")"))
 		b.WriteString("\n")
 
@@ -422,9 +437,7 @@ func renderTimelinePanel(data *stats.TimelineData, width, height int) string {
 			if maxMsgLen < 20 {
 				maxMsgLen = 20
 			}
-			if len(msg) > maxMsgLen {
-				msg = msg[:maxMsgLen-3] + "..."
-			}
+			msg = runewidth.Truncate(msg, maxMsgLen, "...")
 			b.WriteString("    ")
 			b.WriteString(timelineMessageStyle.Render("\"" + msg + "\""))
 			b.WriteString("\n")
@@ -438,6 +451,7 @@ func renderTimelinePanel(data *stats.TimelineData, width, height int) string {
 
 		rowCount += 2
 	}
+	b.WriteString(timedOutNote(data.TimedOut))
 
 	return b.String()
 }
@@ -454,3 +468,414 @@ func timelineDayStyle(dayLabel string) lipgloss.Style {
 		return timelineOlderStyle
 	}
 }
+
+// Submodule panel styling
+var (
+	submodulePathStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF")).Bold(true)
+	submoduleOKStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#22c55e"))
+	submoduleWarnStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#eab308")).Bold(true)
+	submoduleUninitStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#6B7280"))
+)
+
+// renderSubmodulePanel renders the submodules of the selected repo with
+// their state relative to the recorded commit
+func renderSubmodulePanel(repo *model.Repo, width, height int) string {
+	if repo == nil {
+		return panelMutedStyle.Render("No repository selected.")
+	}
+
+	var b strings.Builder
+
+	b.WriteString(panelTitleStyle.Render("📦 Submodules"))
+	b.WriteString("\n")
+	b.WriteString(panelSubtitleStyle.Render(repo.Name))
+	b.WriteString("\n\n")
+
+	subs := repo.Status.Submodules
+	if len(subs) == 0 {
+		b.WriteString(panelMutedStyle.Render("No submodules."))
+		return b.String()
+	}
+
+	maxRows := (height - 6) / 2
+	if maxRows < 3 {
+		maxRows = 3
+	}
+
+	for i, sub := range subs {
+		if i >= maxRows {
+			b.WriteString(panelMutedStyle.Render(fmt.Sprintf("  ... and %d more\n", len(subs)-maxRows)))
+			break
+		}
+
+		maxPathLen := width - 4
+		if maxPathLen < 10 {
+			maxPathLen = 10
+		}
+		path := truncateLeft(sub.Path, maxPathLen)
+
+		b.WriteString(submodulePathStyle.Render(path))
+		b.WriteString("\n  ")
+		b.WriteString(submoduleStateLine(sub))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// submoduleStateLine describes a single submodule's state with an icon
+func submoduleStateLine(sub model.Submodule) string {
+	commit := sub.Commit
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+
+	switch {
+	case sub.Uninitialized:
+		return submoduleUninitStyle.Render("○ uninitialized")
+	case sub.OutOfSync && sub.Dirty:
+		return submoduleWarnStyle.Render("⚠ at " + commit + " (not recorded), dirty")
+	case sub.OutOfSync:
+		return submoduleWarnStyle.Render("⚠ at " + commit + " (not recorded)")
+	case sub.Dirty:
+		return submoduleWarnStyle.Render("● dirty")
+	default:
+		return submoduleOKStyle.Render("✓ " + commit)
+	}
+}
+
+// Unpushed panel styling
+var (
+	unpushedRepoStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF")).Bold(true)
+	unpushedBranchStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#A78BFA"))
+	unpushedCountStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#F97316")).Bold(true)
+)
+
+// renderUnpushedPanel renders the all-branches audit: every local branch,
+// across all repos, with commits that exist on this machine only
+func renderUnpushedPanel(repos []model.Repo, width, height int) string {
+	var b strings.Builder
+
+	b.WriteString(panelTitleStyle.Render("⇡ Unpushed Branches"))
+	b.WriteString("\n")
+
+	// Worktrees share branches with their main repo; list them once
+	present := make(map[string]bool, len(repos))
+	for _, r := range repos {
+		present[r.Path] = true
+	}
+
+	branchCount := 0
+	var audited []model.Repo
+	for _, r := range repos {
+		if r.Kind == model.KindWorktree && present[r.MainRepo] {
+			continue
+		}
+		if len(r.Status.UnpushedBranches) > 0 {
+			audited = append(audited, r)
+			branchCount += len(r.Status.UnpushedBranches)
+		}
+	}
+
+	if len(audited) == 0 {
+		b.WriteString("\n")
+		b.WriteString(panelMutedStyle.Render("Every local branch is on a remote."))
+		return b.String()
+	}
+
+	b.WriteString(panelSubtitleStyle.Render(fmt.Sprintf("%d branches in %d repos", branchCount, len(audited))))
+	b.WriteString("\n\n")
+
+	maxRows := height - 6
+	if maxRows < 5 {
+		maxRows = 5
+	}
+
+	maxNameLen := width - 12
+	if maxNameLen < 10 {
+		maxNameLen = 10
+	}
+
+	rowCount := 0
+	for i, r := range audited {
+		if rowCount >= maxRows {
+			b.WriteString(panelMutedStyle.Render(fmt.Sprintf("  ... and %d more repos\n", len(audited)-i)))
+			break
+		}
+
+		b.WriteString(unpushedRepoStyle.Render(r.Name))
+		b.WriteString("\n")
+		rowCount++
+
+		for _, br := range r.Status.UnpushedBranches {
+			name := truncateString(br.Name, maxNameLen)
+			b.WriteString("  ")
+			b.WriteString(unpushedBranchStyle.Render(name))
+			b.WriteString(" ")
+			b.WriteString(unpushedCountStyle.Render(fmt.Sprintf("+%d", br.Commits)))
+			b.WriteString("\n")
+			rowCount++
+		}
+	}
+
+	return b.String()
+}
+
+// Detail panel styling
+var (
+	detailSectionStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#A78BFA")).Bold(true)
+	detailStagedStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#22c55e")).Bold(true)
+	detailModifiedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#eab308")).Bold(true)
+	detailUntrackedStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#6B7280")).Bold(true)
+	detailConflictedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#ef4444")).Bold(true)
+	detailPathStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
+	detailHashStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("#F59E0B"))
+	detailSelectedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#A78BFA")).Bold(true)
+)
+
+// detailMaxFiles caps the number of changed paths listed in the detail pane
+const detailMaxFiles = 12
+
+// renderDetailPanel renders the changed paths, recent commits, upstream,
+// remotes and stashes of the selected repo
+func renderDetailPanel(repo *model.Repo, data *gitstatus.Detail, loadErr error, cursor int, focused bool, width, height int) string {
+	if repo == nil {
+		return panelMutedStyle.Render("No repository selected.")
+	}
+
+	var b strings.Builder
+
+	b.WriteString(panelTitleStyle.Render("🔎 " + repo.Name))
+	b.WriteString("\n")
+
+	// Branch and upstream
+	upstream := repo.Status.Upstream
+	switch {
+	case repo.Status.UpstreamGone:
+		upstream += " [gone]"
+	case upstream == "":
+		upstream = "no upstream"
+	}
+	b.WriteString(timelineBranchStyle.Render(repo.Status.Branch))
+	b.WriteString(panelMutedStyle.Render(" → " + upstream))
+	b.WriteString("\n")
+
+	// Repos found inside another repo's working tree
+	if repo.Parent != "" {
+		b.WriteString(panelMutedStyle.Render(fmt.Sprintf("↳ %s in %s", repo.Nesting, filepath.Base(repo.Parent))))
+		b.WriteString("\n")
+	}
+	for _, alias := range repo.Aliases {
+		b.WriteString(panelMutedStyle.Render("⇄ also at " + alias))
+		b.WriteString("\n")
+	}
+
+	// git refuses to read the repo at all, so there is nothing to load
+	if repo.Status.Untrusted {
+		b.WriteString("\n")
+		b.WriteString(detailConflictedStyle.Render("⛔ Untrusted repository"))
+		b.WriteString("\n")
+		b.WriteString(panelMutedStyle.Render("git detected dubious ownership: this repo is owned by another user. Trust it with:"))
+		b.WriteString("\n\n")
+		b.WriteString(detailPathStyle.Render(gitstatus.SafeDirectoryFix(repo.Path)))
+		b.WriteString("\n\n")
+		b.WriteString(panelMutedStyle.Render("or list it under git.safeDirectories in the git-scope config."))
+		return b.String()
+	}
+
+	// The status shown in the table is incomplete, say why
+	if repo.Status.ScanError != "" {
+		b.WriteString("\n")
+		b.WriteString(detailConflictedStyle.Render("✗ Scan error"))
+		b.WriteString("\n")
+		b.WriteString(panelMutedStyle.Render(repo.Status.ScanError))
+		b.WriteString("\n")
+	}
+
+	if data == nil && loadErr != nil {
+		b.WriteString("\n")
+		b.WriteString(panelMutedStyle.Render("Could not load details: " + loadErr.Error()))
+		return b.String()
+	}
+	if data == nil {
+		b.WriteString("\n")
+		b.WriteString(panelMutedStyle.Render("Loading details..."))
+		return b.String()
+	}
+
+	maxLen := width - 6
+	if maxLen < 20 {
+		maxLen = 20
+	}
+
+	if repo.Status.Bare != nil {
+		writeBareInfo(&b, repo.Status.Bare, maxLen)
+	} else {
+		// Changed paths
+		b.WriteString("\n")
+		b.WriteString(detailSectionStyle.Render(fmt.Sprintf("Changes (%d)", len(data.Files))))
+		b.WriteString("\n")
+		if len(data.Files) == 0 {
+			b.WriteString(panelMutedStyle.Render("  Working tree clean"))
+			b.WriteString("\n")
+		}
+
+		maxFiles := detailMaxFiles
+		if height > 0 && height/3 < maxFiles {
+			maxFiles = height / 3
+		}
+		if maxFiles < 3 {
+			maxFiles = 3
+		}
+
+		// Scroll the list so the selected file stays visible
+		start := 0
+		if focused && cursor >= maxFiles {
+			start = cursor - maxFiles + 1
+		}
+		if start > 0 {
+			b.WriteString(panelMutedStyle.Render(fmt.Sprintf("  ... %d above\n", start)))
+		}
+		for i := start; i < len(data.Files); i++ {
+			if i >= start+maxFiles {
+				b.WriteString(panelMutedStyle.Render(fmt.Sprintf("  ... and %d more\n", len(data.Files)-i)))
+				break
+			}
+			f := data.Files[i]
+			path := truncateLeft(displayPath(f), maxLen-3)
+
+			if focused && i == cursor {
+				b.WriteString(detailSelectedStyle.Render("▸"))
+				b.WriteString(" ")
+				b.WriteString(fileChangeMarker(f))
+				b.WriteString(" ")
+				b.WriteString(detailSelectedStyle.Render(path))
+			} else {
+				b.WriteString("  ")
+				b.WriteString(fileChangeMarker(f))
+				b.WriteString(" ")
+				b.WriteString(detailPathStyle.Render(path))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	// Recent commits
+	if len(data.Commits) > 0 {
+		b.WriteString("\n")
+		b.WriteString(detailSectionStyle.Render("Recent commits"))
+		b.WriteString("\n")
+		for _, c := range data.Commits {
+			subject := truncateString(c.Subject, maxLen-9)
+			b.WriteString("  ")
+			b.WriteString(detailHashStyle.Render(c.Hash))
+			b.WriteString(" ")
+			b.WriteString(subject)
+			b.WriteString("\n")
+		}
+	}
+
+	// Remotes
+	b.WriteString("\n")
+	b.WriteString(detailSectionStyle.Render("Remotes"))
+	b.WriteString("\n")
+	if len(data.Remotes) == 0 {
+		b.WriteString(panelMutedStyle.Render("  none (local only)"))
+		b.WriteString("\n")
+	}
+	for _, r := range data.Remotes {
+		b.WriteString("  ")
+		b.WriteString(r.Name)
+		b.WriteString(" ")
+		b.WriteString(panelMutedStyle.Render(truncateLeft(r.URL, maxLen-runewidth.StringWidth(r.Name)-1)))
+		b.WriteString("\n")
+	}
+
+	// Stashes
+	if len(data.Stashes) > 0 {
+		b.WriteString("\n")
+		b.WriteString(detailSectionStyle.Render(fmt.Sprintf("Stashes (%d)", len(data.Stashes))))
+		b.WriteString("\n")
+		now := time.Now()
+		for _, s := range data.Stashes {
+			msg := truncateString(s.Message, maxLen-12)
+			b.WriteString("  ")
+			b.WriteString(msg)
+			b.WriteString(panelMutedStyle.Render(" · " + stats.FormatTimeAgo(s.Time, now)))
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
+// writeBareInfo writes what the detail pane shows of a bare repository in
+// place of its changes: size, last fetch and the branches by last commit
+func writeBareInfo(b *strings.Builder, bare *model.BareInfo, maxLen int) {
+	now := time.Now()
+	kind := "Bare repository"
+	if bare.Mirror {
+		kind = "Mirror"
+	}
+	fetched := "never fetched"
+	if !bare.LastFetch.IsZero() {
+		fetched = "fetched " + stats.FormatTimeAgo(bare.LastFetch, now)
+	}
+
+	b.WriteString("\n")
+	b.WriteString(panelMutedStyle.Render(fmt.Sprintf("%s · %s · %s", kind, stats.FormatBytes(bare.SizeBytes), fetched)))
+	b.WriteString("\n\n")
+	b.WriteString(detailSectionStyle.Render(fmt.Sprintf("Branches (%d)", len(bare.Branches))))
+	b.WriteString("\n")
+	for i, br := range bare.Branches {
+		if i >= detailMaxFiles {
+			b.WriteString(panelMutedStyle.Render(fmt.Sprintf("  ... and %d more\n", len(bare.Branches)-i)))
+			break
+		}
+		b.WriteString("  ")
+		b.WriteString(truncateString(br.Name, maxLen-16))
+		if !br.LastCommit.IsZero() {
+			b.WriteString(panelMutedStyle.Render(" · " + stats.FormatTimeAgo(br.LastCommit, now)))
+		}
+		b.WriteString("\n")
+	}
+}
+
+// fileChangeMarker returns a colored two-letter marker for a changed path:
+// staged (S), modified (M), untracked (?) or conflicted (U)
+func fileChangeMarker(f gitstatus.FileChange) string {
+	switch {
+	case f.Conflicted:
+		return detailConflictedStyle.Render("UU")
+	case f.Untracked:
+		return detailUntrackedStyle.Render("??")
+	}
+
+	x, y := " ", " "
+	if f.Staged {
+		x = detailStagedStyle.Render("S")
+	}
+	if f.Unstaged {
+		y = detailModifiedStyle.Render("M")
+	}
+	return x + y
+}
+
+// displayPath returns the path of a change, showing the source of renames
+func displayPath(f gitstatus.FileChange) string {
+	if f.OrigPath != "" {
+		return f.OrigPath + " → " + f.Path
+	}
+	return f.Path
+}
+
+// truncateLeft shortens a string from the left to at most maxLen
+// terminal cells, keeping its end visible, which is the informative part
+// of paths and URLs
+func truncateLeft(s string, maxLen int) string {
+	width := runewidth.StringWidth(s)
+	if maxLen < 2 || width <= maxLen {
+		return s
+	}
+	return runewidth.TruncateLeft(s, width-maxLen+1, "…")
+}
@@ -85,12 +85,30 @@ var (
 			Padding(0, 1).
 			Bold(true)
 
+	unpublishedBadgeStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#000000")).
+				Background(lipgloss.Color("#F97316")).
+				Padding(0, 1).
+				Bold(true)
+
 	cleanBadgeStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#000000")).
 			Background(cleanColor).
 			Padding(0, 1).
 			Bold(true)
 
+	errorBadgeStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#000000")).
+			Background(errorColor).
+			Padding(0, 1).
+			Bold(true)
+
+	bareBadgeStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#000000")).
+			Background(lipgloss.Color("#A78BFA")).
+			Padding(0, 1).
+			Bold(true)
+
 	// Table styles - bordered container
 	tableContainerStyle = lipgloss.NewStyle().
 				BorderStyle(lipgloss.RoundedBorder()).
@@ -182,6 +200,17 @@ var (
 	cleanDotStyle = lipgloss.NewStyle().
 			Foreground(cleanColor)
 
+	unpublishedDotStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#F97316")).
+				Bold(true)
+
+	errorDotStyle = lipgloss.NewStyle().
+			Foreground(errorColor).
+			Bold(true)
+
+	bareDotStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#A78BFA"))
+
 	legendStyle = lipgloss.NewStyle().
 			Foreground(textTertiary)
 )
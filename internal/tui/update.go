@@ -1,14 +1,23 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/Bharath-code/git-scope/internal/browser"
+	"github.com/Bharath-code/git-scope/internal/cache"
+	"github.com/Bharath-code/git-scope/internal/config"
+	"github.com/Bharath-code/git-scope/internal/gitstatus"
 	"github.com/Bharath-code/git-scope/internal/model"
 	"github.com/Bharath-code/git-scope/internal/nudge"
 	"github.com/Bharath-code/git-scope/internal/scan"
 	"github.com/Bharath-code/git-scope/internal/stats"
+	"github.com/Bharath-code/git-scope/internal/watch"
 	"github.com/Bharath-code/git-scope/internal/workspace"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -26,10 +35,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		m.resizeTable()
+		m.resizeDiffView()
 
 	case spinner.TickMsg:
-		// Update spinner during loading
-		if m.state == StateLoading {
+		// Update spinner while loading or while a scan is still streaming
+		if m.state == StateLoading || m.activeScan != nil {
 			m.spinner, cmd = m.spinner.Update(msg)
 			cmds = append(cmds, cmd)
 		}
@@ -39,6 +49,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.state = StateReady
 		m.resetPage()
 		m.updateTable()
+		m.watchRepos()
 
 		// Show helpful message if no repos found
 		if len(msg.repos) == 0 {
@@ -50,36 +61,40 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case scanErrorMsg:
-		m.state = StateError
-		m.err = msg.err
-		return m, nil
-
-	case workspaceScanCompleteMsg:
-		m.repos = msg.repos
-		m.state = StateReady
+	case scanStartedMsg:
+		// A newer scan was started in the meantime: drain this one unseen
+		if msg.stream.id != m.scanSeq {
+			return m, waitForReposCmd(msg.stream)
+		}
+		m.activeScan = msg.stream
+		m.repos = nil
 		m.resetPage()
 		m.updateTable()
+		return m, tea.Batch(waitForReposCmd(msg.stream), m.spinner.Tick)
 
-		// Show helpful message about switched workspace
-		if len(msg.repos) == 0 {
-			m.statusMsg = fmt.Sprintf("⚠️  No git repos found in %s", msg.workspacePath)
-		} else {
-			m.statusMsg = fmt.Sprintf("✓ Switched to %s (%d repos)", msg.workspacePath, len(msg.repos))
-
-			// Trigger star nudge after successful workspace switch
-			if nudge.ShouldShowNudge() && !m.nudgeShownThisSession {
-				m.showStarNudge = true
-				m.nudgeShownThisSession = true
-				nudge.MarkShown()
-			}
+	case reposFoundMsg:
+		if msg.stream != m.activeScan {
+			return m, waitForReposCmd(msg.stream)
 		}
-		return m, nil
+		// A repo comes again when the scan found another path to it
+		index := make(map[string]int, len(m.repos))
+		for i, r := range m.repos {
+			index[r.Path] = i
+		}
+		for _, r := range msg.repos {
+			m.repos = scan.AddRepo(m.repos, index, r)
+		}
+		if m.state == StateLoading {
+			m.state = StateReady
+		}
+		m.updateTableKeepSelection()
+		return m, waitForReposCmd(msg.stream)
 
-	case workspaceScanErrorMsg:
-		m.state = StateError
-		m.err = msg.err
-		return m, nil
+	case scanFinishedMsg:
+		if msg.stream != m.activeScan {
+			return m, nil
+		}
+		return m.finishScan(msg.stream)
 
 	case openEditorMsg:
 		// Parse editor command (handles "editor --flag" style configs)
@@ -98,19 +113,82 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		args := append(fields[1:], msg.path)
 		c := exec.Command(fields[0], args...)
 		return m, tea.ExecProcess(c, func(err error) tea.Msg {
-			if err != nil {
-				return editorClosedMsg{err: err}
-			}
-			return editorClosedMsg{}
+			return editorClosedMsg{path: msg.path, err: err}
 		})
 
 	case editorClosedMsg:
 		if msg.err != nil {
 			m.statusMsg = "Error: " + msg.err.Error()
+			return m, nil
+		}
+		// Only the edited repo can have changed
+		m.statusMsg = "Refreshing " + filepath.Base(msg.path) + "..."
+		return m, refreshRepoCmd(m.scanOptions(), msg.path)
+
+	case repoRefreshedMsg:
+		if msg.err != nil {
+			m.statusMsg = "❌ " + msg.err.Error()
+			return m, nil
+		}
+		m.patchRepo(msg.repo)
+		m.statusMsg = "✓ Refreshed " + msg.repo.Name
+		// Reload the detail pane if it shows the refreshed repo
+		if m.activePanel == PanelDetail && m.detailPath == msg.repo.Path {
+			m.detailPath = ""
+			return m, m.syncDetailCmd()
+		}
+		return m, nil
+
+	case reposChangedMsg:
+		// Auto-refresh was turned off or restarted since
+		if msg.watcher != m.watcher {
+			return m, nil
+		}
+		return m, tea.Batch(refreshChangedCmd(m.scanOptions(), msg.paths), waitForChangesCmd(msg.watcher))
+
+	case changesRefreshedMsg:
+		if m.watcher == nil {
+			return m, nil
+		}
+		var names []string
+		reloadDetail := false
+		for _, repo := range msg.repos {
+			if old := m.repoByPath(repo.Path); old != nil && rowChanged(*old, repo) {
+				m.highlighted[repo.Path] = time.Now().Add(highlightDuration)
+				names = append(names, repo.Name)
+			}
+			m.patchRepo(repo)
+			if m.activePanel == PanelDetail && m.detailPath == repo.Path {
+				reloadDetail = true
+			}
+		}
+		if len(names) == 0 {
+			return m, nil
+		}
+
+		if len(names) <= 3 {
+			m.statusMsg = "↻ " + strings.Join(names, ", ") + " changed"
 		} else {
-			m.statusMsg = ""
+			m.statusMsg = fmt.Sprintf("↻ %d repos changed", len(names))
+		}
+		cmds := []tea.Cmd{tea.Tick(highlightDuration, func(time.Time) tea.Msg {
+			return highlightExpiredMsg{}
+		})}
+		if reloadDetail {
+			m.detailPath = ""
+			cmds = append(cmds, m.syncDetailCmd())
 		}
-		return m, scanReposCmd(m.cfg, true)
+		return m, tea.Batch(cmds...)
+
+	case highlightExpiredMsg:
+		now := time.Now()
+		for path, until := range m.highlighted {
+			if !now.Before(until) {
+				delete(m.highlighted, path)
+			}
+		}
+		m.table.SetRows(m.pageRows())
+		return m, nil
 
 	case grassDataLoadedMsg:
 		m.grassData = msg.data
@@ -133,7 +211,43 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case detailLoadedMsg:
+		// Ignore results for a repo the cursor already moved away from
+		if msg.path == m.detailPath {
+			m.detailData = msg.data
+			m.detailErr = msg.err
+			m.detailCursor = 0
+			if msg.err != nil {
+				m.statusMsg = "❌ " + msg.err.Error()
+			} else if msg.data != nil {
+				m.statusMsg = fmt.Sprintf("🔎 %d changed files", len(msg.data.Files))
+			}
+		}
+		return m, nil
+
+	case diffLoadedMsg:
+		if msg.err != nil {
+			m.statusMsg = "❌ " + msg.err.Error()
+			return m, nil
+		}
+		m.state = StateDiff
+		m.diffTitle = msg.title
+		m.diffView.SetContent(colorizeDiff(msg.content, msg.path))
+		m.diffView.GotoTop()
+		m.statusMsg = ""
+		return m, nil
+
 	case tea.KeyMsg:
+		// Handle diff preview separately
+		if m.state == StateDiff {
+			return m.handleDiffMode(msg)
+		}
+
+		// Handle detail pane file selection separately
+		if m.activePanel == PanelDetail && m.detailFocus {
+			return m.handleDetailFocusMode(msg)
+		}
+
 		// Handle search mode separately
 		if m.state == StateSearching {
 			return m.handleSearchMode(msg)
@@ -179,15 +293,46 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
-		case "r":
+		case "r", "R":
+			// r re-checks only repos whose git files changed, R all of them
+			var known map[string]model.Repo
+			if msg.String() == "r" {
+				known = scan.ByPath(m.repos)
+			}
+			id := m.beginScan()
 			m.state = StateLoading
+			m.activeWorkspace = "" // Rescans cover the config roots
 			m.statusMsg = "Rescanning..."
-			return m, scanReposCmd(m.cfg, true)
+			return m, tea.Batch(scanReposCmd(m.cfg, true, known, id), m.spinner.Tick)
+
+		case "a":
+			// Toggle auto-refresh
+			if m.state == StateReady {
+				if m.watcher != nil {
+					m.watcher.Close()
+					m.watcher = nil
+					m.statusMsg = "Auto-refresh off"
+					return m, nil
+				}
+				m.watcher = watch.New(watch.Options{})
+				m.watchRepos()
+				m.statusMsg = fmt.Sprintf("👁 Auto-refresh on: watching %d repos (%s)", len(m.repos), m.watcher.Backend())
+				return m, waitForChangesCmd(m.watcher)
+			}
+
+		case "u":
+			// Refresh the selected repo only
+			if m.state == StateReady {
+				if repo := m.GetSelectedRepo(); repo != nil {
+					m.statusMsg = "Refreshing " + repo.Name + "..."
+					return m, refreshRepoCmd(m.scanOptions(), repo.Path)
+				}
+			}
 
 		case "f":
 			// Cycle through filter modes
 			if m.state == StateReady {
-				m.filterMode = (m.filterMode + 1) % 3
+				m.filterMode = (m.filterMode + 1) % filterModeCount
 				m.resetPage()
 				m.updateTable()
 				m.statusMsg = "Filter: " + m.GetFilterModeName()
@@ -196,7 +341,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case "s":
 			if m.state == StateReady {
-				m.sortMode = (m.sortMode + 1) % 4
+				m.sortMode = (m.sortMode + 1) % sortModeCount
 				m.resetPage()
 				m.updateTable()
 				m.statusMsg = "Sorted by: " + m.GetSortModeName()
@@ -239,6 +384,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
+		case "5":
+			if m.state == StateReady {
+				m.sortMode = SortByStash
+				m.resetPage()
+				m.updateTable()
+				m.statusMsg = "Sorted by: Stashes"
+				return m, nil
+			}
+
 		case "c":
 			// Clear search and filters
 			if m.state == StateReady {
@@ -275,7 +429,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				} else {
 					m.activePanel = PanelGrass
 					m.statusMsg = "🌿 Loading contribution graph..."
-					return m, loadGrassDataCmd(m.repos)
+					return m, loadGrassDataCmd(m.repos, m.repoTimeout())
 				}
 				return m, nil
 			}
@@ -289,7 +443,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				} else {
 					m.activePanel = PanelDisk
 					m.statusMsg = "💾 Calculating disk usage..."
-					return m, loadDiskDataCmd(m.repos)
+					return m, loadDiskDataCmd(m.repos, m.repoTimeout())
 				}
 				return m, nil
 			}
@@ -303,12 +457,71 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				} else {
 					m.activePanel = PanelTimeline
 					m.statusMsg = "⏰ Loading timeline..."
-					return m, loadTimelineDataCmd(m.repos)
+					return m, loadTimelineDataCmd(m.repos, m.repoTimeout())
+				}
+				return m, nil
+			}
+
+		case "m":
+			// Toggle submodule detail for the selected repo
+			if m.state == StateReady {
+				if m.activePanel == PanelSubmodules {
+					m.activePanel = PanelNone
+					m.statusMsg = ""
+				} else {
+					m.activePanel = PanelSubmodules
+					if repo := m.GetSelectedRepo(); repo != nil {
+						m.statusMsg = fmt.Sprintf("📦 %d submodules in %s", len(repo.Status.Submodules), repo.Name)
+					}
+				}
+				return m, nil
+			}
+
+		case "i":
+			// Toggle the detail pane for the selected repo
+			if m.state == StateReady {
+				if m.activePanel == PanelDetail {
+					m.activePanel = PanelNone
+					m.statusMsg = ""
+					return m, nil
+				}
+				m.activePanel = PanelDetail
+				m.detailFocus = false
+				m.detailPath = ""
+				return m, m.syncDetailCmd()
+			}
+
+		case "tab":
+			// Focus the file list of the detail pane to pick a diff
+			if m.state == StateReady && m.activePanel == PanelDetail &&
+				m.detailData != nil && len(m.detailData.Files) > 0 {
+				m.detailFocus = true
+				m.statusMsg = "Select a file and press enter to preview its diff"
+				return m, nil
+			}
+
+		case "p":
+			// Toggle the unpushed branches audit
+			if m.state == StateReady {
+				if m.activePanel == PanelUnpushed {
+					m.activePanel = PanelNone
+					m.statusMsg = ""
+				} else {
+					m.activePanel = PanelUnpushed
+					m.statusMsg = "⇡ Branches with commits not on any remote"
 				}
 				return m, nil
 			}
 
 		case "esc":
+			// Cancel a running scan, keeping the repos already loaded.
+			// Once the table is usable, an open panel is closed first.
+			if m.activeScan != nil && (m.state == StateLoading || m.activePanel == PanelNone) {
+				m.activeScan.cancel()
+				m.statusMsg = "Cancelling scan..."
+				return m, nil
+			}
+
 			// Close panel if open
 			if m.activePanel != PanelNone {
 				m.activePanel = PanelNone
@@ -355,9 +568,29 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Update the table
 	m.table, cmd = m.table.Update(msg)
 	cmds = append(cmds, cmd)
+
+	// Keep the detail pane in sync with the cursor
+	if m.activePanel == PanelDetail {
+		cmds = append(cmds, m.syncDetailCmd())
+	}
 	return m, tea.Batch(cmds...)
 }
 
+// syncDetailCmd starts loading detail data when the selected repo differs
+// from the one currently shown in the detail pane
+func (m *Model) syncDetailCmd() tea.Cmd {
+	repo := m.GetSelectedRepo()
+	if repo == nil || repo.Path == m.detailPath {
+		return nil
+	}
+
+	m.detailPath = repo.Path
+	m.detailData = nil
+	m.detailErr = nil
+	m.statusMsg = "🔎 Loading details for " + repo.Name + "..."
+	return loadDetailCmd(repo.Path, m.repoTimeout())
+}
+
 // handleSearchMode handles key events when in search mode
 func (m Model) handleSearchMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
@@ -400,7 +633,8 @@ func (m Model) handleSearchMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // editorClosedMsg is sent when the editor process closes
 type editorClosedMsg struct {
-	err error
+	path string
+	err  error
 }
 
 // grassDataLoadedMsg is sent when contribution data is loaded
@@ -409,9 +643,11 @@ type grassDataLoadedMsg struct {
 }
 
 // loadGrassDataCmd loads contribution data from all repos
-func loadGrassDataCmd(repos []model.Repo) tea.Cmd {
+func loadGrassDataCmd(repos []model.Repo, timeout time.Duration) tea.Cmd {
 	return func() tea.Msg {
-		data, _ := stats.GetContributions(repos, 12) // Last 12 weeks
+		// Each repo has its own deadline, so a hung one is skipped
+		// instead of stalling the panel
+		data, _ := stats.GetContributions(context.Background(), repos, 12, timeout) // Last 12 weeks
 		return grassDataLoadedMsg{data: data}
 	}
 }
@@ -422,9 +658,9 @@ type diskDataLoadedMsg struct {
 }
 
 // loadDiskDataCmd loads disk usage data from all repos
-func loadDiskDataCmd(repos []model.Repo) tea.Cmd {
+func loadDiskDataCmd(repos []model.Repo, timeout time.Duration) tea.Cmd {
 	return func() tea.Msg {
-		data, _ := stats.GetDiskUsage(repos)
+		data, _ := stats.GetDiskUsage(context.Background(), repos, timeout)
 		return diskDataLoadedMsg{data: data}
 	}
 }
@@ -435,13 +671,97 @@ type timelineDataLoadedMsg struct {
 }
 
 // loadTimelineDataCmd loads timeline data from all repos
-func loadTimelineDataCmd(repos []model.Repo) tea.Cmd {
+func loadTimelineDataCmd(repos []model.Repo, timeout time.Duration) tea.Cmd {
 	return func() tea.Msg {
-		data, _ := stats.GetTimeline(repos)
+		data, _ := stats.GetTimeline(context.Background(), repos, timeout)
 		return timelineDataLoadedMsg{data: data}
 	}
 }
 
+// detailLoadedMsg is sent when the detail data of a repo is loaded
+type detailLoadedMsg struct {
+	path string
+	data *gitstatus.Detail
+	err  error
+}
+
+// detailCommitCount is the number of recent commits shown in the detail pane
+const detailCommitCount = 5
+
+// loadDetailCmd loads the changed files, commits, remotes and stashes of a repo
+func loadDetailCmd(repoPath string, timeout time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		data, err := gitstatus.GetDetail(ctx, repoPath, detailCommitCount)
+		return detailLoadedMsg{path: repoPath, data: data, err: err}
+	}
+}
+
+// repoRefreshedMsg is sent when a single repo has been checked again
+type repoRefreshedMsg struct {
+	repo model.Repo
+	err  error
+}
+
+// refreshRepoCmd collects the status of one repo again and replaces it
+// in the cache, if it is cached
+func refreshRepoCmd(opts scan.Options, repoPath string) tea.Cmd {
+	return func() tea.Msg {
+		repo, err := scan.Refresh(context.Background(), opts, repoPath)
+		if err != nil {
+			return repoRefreshedMsg{err: err}
+		}
+		_ = cache.NewFileStore().UpdateRepos(repo)
+		return repoRefreshedMsg{repo: repo}
+	}
+}
+
+// highlightDuration is how long a row stays marked after its status changed
+const highlightDuration = 3 * time.Second
+
+// reposChangedMsg delivers the repos the watcher saw change on disk
+type reposChangedMsg struct {
+	watcher *watch.Watcher
+	paths   []string
+}
+
+// changesRefreshedMsg is sent when the repos of a reposChangedMsg have
+// been checked again
+type changesRefreshedMsg struct {
+	repos []model.Repo
+}
+
+// highlightExpiredMsg is sent when changed rows should lose their marker
+type highlightExpiredMsg struct{}
+
+// waitForChangesCmd waits for the next batch of changed repos. It returns
+// no message once the watcher is closed.
+func waitForChangesCmd(w *watch.Watcher) tea.Cmd {
+	return func() tea.Msg {
+		paths, ok := <-w.Changes()
+		if !ok {
+			return nil
+		}
+		return reposChangedMsg{watcher: w, paths: paths}
+	}
+}
+
+// refreshChangedCmd checks changed repos again and updates them in the
+// cache. Repos that are gone are left out.
+func refreshChangedCmd(opts scan.Options, paths []string) tea.Cmd {
+	return func() tea.Msg {
+		repos := make([]model.Repo, 0, len(paths))
+		for _, path := range paths {
+			if repo, err := scan.Refresh(context.Background(), opts, path); err == nil {
+				repos = append(repos, repo)
+			}
+		}
+		_ = cache.NewFileStore().UpdateRepos(repos...)
+		return changesRefreshedMsg{repos: repos}
+	}
+}
+
 // handleWorkspaceSwitchMode handles key events when in workspace switch mode
 func (m Model) handleWorkspaceSwitchMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
@@ -468,13 +788,14 @@ func (m Model) handleWorkspaceSwitchMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 		// Switch to loading state and scan the new workspace
+		id := m.beginScan()
 		m.state = StateLoading
 		m.workspaceInput.Blur()
 		m.workspaceError = ""
 		m.activeWorkspace = normalizedPath
 		m.statusMsg = "🔄 Switching to " + normalizedPath + "..."
 
-		return m, scanWorkspaceCmd(normalizedPath, m.cfg.Ignore)
+		return m, tea.Batch(scanWorkspaceCmd(m.cfg, normalizedPath, id), m.spinner.Tick)
 
 	case "tab":
 		// Tab completion for directory paths
@@ -505,30 +826,90 @@ func (m Model) handleWorkspaceSwitchMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-// workspaceScanCompleteMsg is sent when workspace scanning is complete
-type workspaceScanCompleteMsg struct {
-	repos         []model.Repo
-	workspacePath string
+// scanWorkspaceCmd starts a background scan of a single workspace path
+func scanWorkspaceCmd(cfg *config.Config, workspacePath string, id int) tea.Cmd {
+	return func() tea.Msg {
+		opts := workspaceOptions(cfg, workspacePath)
+		return scanStartedMsg{stream: startScan(id, opts, workspacePath, nil)}
+	}
 }
 
-// workspaceScanErrorMsg is sent when workspace scanning fails
-type workspaceScanErrorMsg struct {
-	err error
+// workspaceOptions scans a single workspace path instead of the config roots
+func workspaceOptions(cfg *config.Config, workspacePath string) scan.Options {
+	opts := scan.OptionsFor(cfg)
+	opts.Roots = config.RootsFor([]string{workspacePath})
+	opts.Repos = nil
+	return opts
 }
 
-// scanWorkspaceCmd scans a single workspace path for repositories
-func scanWorkspaceCmd(workspacePath string, ignore []string) tea.Cmd {
-	return func() tea.Msg {
-		repos, err := scan.ScanRoots([]string{workspacePath}, ignore)
-		if err != nil {
-			return workspaceScanErrorMsg{err: err}
+// scanOptions returns the options the repos on screen are scanned with,
+// so refreshed repos get the same parent and nesting
+func (m Model) scanOptions() scan.Options {
+	if m.activeWorkspace != "" {
+		return workspaceOptions(m.cfg, m.activeWorkspace)
+	}
+	return scan.OptionsFor(m.cfg)
+}
+
+// beginScan starts tracking a new scan and returns its ID. Repos still
+// arriving from earlier scans are drained and ignored from now on.
+func (m *Model) beginScan() int {
+	m.scanSeq++
+	if m.activeScan != nil {
+		m.activeScan.cancel()
+		m.activeScan = nil
+	}
+	return m.scanSeq
+}
+
+// finishScan leaves the streaming state once a scan has delivered every repo
+func (m Model) finishScan(s *scanStream) (tea.Model, tea.Cmd) {
+	m.activeScan = nil
+
+	cancelled := errors.Is(s.err, context.Canceled)
+	if s.err != nil && !cancelled && len(m.repos) == 0 {
+		m.state = StateError
+		m.err = s.err
+		return m, nil
+	}
+	if m.state == StateLoading {
+		m.state = StateReady
+	}
+	m.updateTableKeepSelection()
+	m.watchRepos()
+
+	elapsed := time.Since(s.started).Round(100 * time.Millisecond)
+	if cancelled {
+		m.statusMsg = fmt.Sprintf("Scan cancelled after %s (%d repos loaded). Press 'r' to rescan.", elapsed, len(m.repos))
+		return m, nil
+	}
+
+	if s.workspace == "" {
+		if len(m.repos) == 0 {
+			m.statusMsg = "⚠️  No git repos found in configured directories. Press 'r' to rescan or run 'git-scope init' to configure."
+		} else {
+			m.statusMsg = fmt.Sprintf("✓ Found %d repos in %s", len(m.repos), elapsed)
+			if reused := s.progress.Reused(); reused > 0 {
+				m.statusMsg += fmt.Sprintf(" (%d re-checked, %d unchanged)", len(m.repos)-int(reused), reused)
+			}
 		}
+		return m, nil
+	}
 
-		return workspaceScanCompleteMsg{
-			repos:         repos,
-			workspacePath: workspacePath,
+	// Show helpful message about switched workspace
+	if len(m.repos) == 0 {
+		m.statusMsg = fmt.Sprintf("⚠️  No git repos found in %s", s.workspace)
+	} else {
+		m.statusMsg = fmt.Sprintf("✓ Switched to %s (%d repos)", s.workspace, len(m.repos))
+
+		// Trigger star nudge after successful workspace switch
+		if nudge.ShouldShowNudge() && !m.nudgeShownThisSession {
+			m.showStarNudge = true
+			m.nudgeShownThisSession = true
+			nudge.MarkShown()
 		}
 	}
+	return m, nil
 }
 
 // openBrowserCmd opens a URL in the default browser
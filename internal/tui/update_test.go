@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bharath-code/git-scope/internal/config"
+	"github.com/Bharath-code/git-scope/internal/model"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// TestDetailLoadError checks that a repo whose details cannot be loaded
+// shows the error instead of loading forever
+func TestDetailLoadError(t *testing.T) {
+	repo := model.Repo{Name: "gone", Path: filepath.Join(t.TempDir(), "gone")}
+
+	m := NewModel(&config.Config{PageSize: 10})
+	m.detailPath = repo.Path
+
+	msg := loadDetailCmd(repo.Path, time.Minute)()
+	loaded, ok := msg.(detailLoadedMsg)
+	if !ok {
+		t.Fatalf("loadDetailCmd returned %T", msg)
+	}
+	if loaded.err == nil {
+		t.Fatal("loading the details of a missing repo did not fail")
+	}
+
+	updated, _ := m.Update(loaded)
+	m = updated.(Model)
+	if m.detailErr == nil {
+		t.Fatal("detail error was dropped")
+	}
+
+	pane := renderDetailPanel(&repo, m.detailData, m.detailErr, 0, false, 80, 30)
+	if strings.Contains(pane, "Loading details") {
+		t.Errorf("pane still loading after the error:\n%s", pane)
+	}
+	if !strings.Contains(pane, "Could not load details") {
+		t.Errorf("pane does not show the error:\n%s", pane)
+	}
+}
+
+// TestColorizeDiffKeepsLines checks that highlighting keeps every line
+// of a diff and its text, whether or not a lexer knows the file
+func TestColorizeDiffKeepsLines(t *testing.T) {
+	diff := strings.Join([]string{
+		"diff --git a/main.go b/main.go",
+		"index 1111111..2222222 100644",
+		"--- a/main.go",
+		"+++ b/main.go",
+		"@@ -1,4 +1,4 @@",
+		" /* a comment",
+		"-   spanning lines */",
+		"+   spanning two lines */",
+		"",
+		"--- not a header",
+		"\\ No newline at end of file",
+	}, "\n")
+
+	for _, path := range []string{"main.go", "notes.unknown-ext"} {
+		got := strings.Split(ansi.Strip(colorizeDiff(diff, path)), "\n")
+		want := strings.Split(diff, "\n")
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("%s: colorized diff changed its text:\ngot  %q\nwant %q", path, got, want)
+		}
+	}
+}
+
+func TestTruncateByWidth(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"ascii", truncateString("repository", 5), "repo…"},
+		{"fits", truncateString("↳ api", 5), "↳ api"},
+		{"prefixed", truncateString("✦ café-service", 7), "✦ café…"},
+		{"wide runes", truncateString("日本語のリポジトリ", 7), "日本語…"},
+		{"left", truncateLeft("/home/ü/code/api", 8), "…ode/api"},
+		{"left wide", truncateLeft("/src/日本語", 5), "…本語"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+// TestRefreshKeepsWorkspaceParent checks that refreshing a repo after a
+// workspace switch finds its parent below the workspace, not the config roots
+func TestRefreshKeepsWorkspaceParent(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("HOME", t.TempDir()) // Keep the cache update away from the real one
+
+	ws, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	outer := filepath.Join(ws, "outer")
+	nested := filepath.Join(outer, "vendor", "nested")
+	for _, dir := range []string{outer, nested} {
+		if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+			t.Fatalf("git init: %v\n%s", err, out)
+		}
+	}
+
+	m := NewModel(&config.Config{PageSize: 10, Roots: config.RootsFor([]string{t.TempDir()})})
+	m.activeWorkspace = ws
+
+	msg, ok := refreshRepoCmd(m.scanOptions(), nested)().(repoRefreshedMsg)
+	if !ok || msg.err != nil {
+		t.Fatalf("refresh failed: %+v", msg)
+	}
+	if msg.repo.Parent != outer {
+		t.Errorf("parent = %q, want %q", msg.repo.Parent, outer)
+	}
+}
@@ -3,7 +3,9 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/Bharath-code/git-scope/internal/scan"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -25,6 +27,8 @@ func (m Model) renderContent() string {
 		b.WriteString(m.renderDashboard())
 	case StateWorkspaceSwitch:
 		b.WriteString(m.renderWorkspaceModal())
+	case StateDiff:
+		b.WriteString(m.renderDiffView())
 	}
 
 	return b.String()
@@ -38,7 +42,12 @@ func (m Model) renderLoading() string {
 	b.WriteString(m.spinner.View())
 	b.WriteString(" ")
 	b.WriteString(loadingStyle.Render("Scanning repositories..."))
-	b.WriteString("\n\n")
+	b.WriteString("\n")
+	if m.activeScan != nil {
+		b.WriteString(m.renderScanProgress())
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
 
 	b.WriteString(subtitleStyle.Render("Searching for git repos in:"))
 	b.WriteString("\n")
@@ -49,7 +58,7 @@ func (m Model) renderLoading() string {
 		b.WriteString(pathStyle.Render(m.activeWorkspace))
 		b.WriteString("\n")
 	} else {
-		for _, root := range m.cfg.Roots {
+		for _, root := range m.cfg.RootPaths() {
 			b.WriteString(pathBulletStyle.Render("  → "))
 			b.WriteString(pathStyle.Render(root))
 			b.WriteString("\n")
@@ -57,7 +66,7 @@ func (m Model) renderLoading() string {
 	}
 	b.WriteString("\n")
 
-	b.WriteString(helpStyle.Render("Press " + helpKeyStyle.Render("q") + " to quit"))
+	b.WriteString(helpStyle.Render("Press " + helpKeyStyle.Render("esc") + " to cancel, " + helpKeyStyle.Render("q") + " to quit"))
 
 	return b.String()
 }
@@ -112,6 +121,12 @@ func (m Model) renderDashboard() string {
 	b.WriteString(m.renderStats())
 	b.WriteString("\n")
 
+	// Live progress while the scan is still filling the table
+	if m.activeScan != nil {
+		b.WriteString(m.spinner.View() + " " + m.renderScanProgress())
+		b.WriteString("\n")
+	}
+
 	// Search bar (show when searching or has active search)
 	if m.state == StateSearching {
 		b.WriteString(m.renderSearchBar())
@@ -138,6 +153,12 @@ func (m Model) renderDashboard() string {
 			panelContent = renderDiskPanel(m.diskData, m.width/2, m.height-15)
 		case PanelTimeline:
 			panelContent = renderTimelinePanel(m.timelineData, m.width/2, m.height-15)
+		case PanelSubmodules:
+			panelContent = renderSubmodulePanel(m.GetSelectedRepo(), m.width/2, m.height-15)
+		case PanelUnpushed:
+			panelContent = renderUnpushedPanel(m.repos, m.width/2, m.height-15)
+		case PanelDetail:
+			panelContent = renderDetailPanel(m.GetSelectedRepo(), m.detailData, m.detailErr, m.detailCursor, m.detailFocus, m.width/2, m.height-15)
 		}
 
 		b.WriteString(renderSplitPane(tableContent, panelContent, m.width-4))
@@ -208,12 +229,23 @@ func (m Model) renderStats() string {
 	shown := len(m.sortedRepos)
 	dirty := 0
 	clean := 0
+	unpublished := 0
+	errored := 0
+	bare := 0
 	for _, r := range m.repos {
-		if r.Status.IsDirty {
+		switch {
+		case r.Status.ScanError != "":
+			errored++
+		case r.Status.Bare != nil:
+			bare++
+		case r.Status.IsDirty:
 			dirty++
-		} else {
+		default:
 			clean++
 		}
+		if scan.HasUnpublishedWork(r.Status) {
+			unpublished++
+		}
 	}
 
 	stats := []string{}
@@ -231,6 +263,15 @@ func (m Model) renderStats() string {
 	if clean > 0 {
 		stats = append(stats, cleanBadgeStyle.Render(fmt.Sprintf("✓ %d clean", clean)))
 	}
+	if unpublished > 0 {
+		stats = append(stats, unpublishedBadgeStyle.Render(fmt.Sprintf("⇡ %d unpublished", unpublished)))
+	}
+	if errored > 0 {
+		stats = append(stats, errorBadgeStyle.Render(fmt.Sprintf("✗ %d errors", errored)))
+	}
+	if bare > 0 {
+		stats = append(stats, bareBadgeStyle.Render(fmt.Sprintf("🗄 %d bare", bare)))
+	}
 
 	// Filter indicator with inline hint
 	if m.filterMode != FilterAll {
@@ -253,6 +294,17 @@ func (m Model) renderStats() string {
 	sortHint := hintStyle.Render(" (s)")
 	stats = append(stats, sortBadge+sortHint)
 
+	// Auto-refresh indicator
+	if m.watcher != nil {
+		liveBadge := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#000000")).
+			Background(lipgloss.Color("#22D3EE")).
+			Padding(0, 1).
+			Render("👁 Live")
+		liveHint := hintStyle.Render(" (a)")
+		stats = append(stats, liveBadge+liveHint)
+	}
+
 	// Pagination indicator (only show if more than one page)
 	totalPages := m.getTotalPages()
 	if totalPages > 1 {
@@ -268,13 +320,27 @@ func (m Model) renderStats() string {
 	return lipgloss.JoinHorizontal(lipgloss.Center, stats...)
 }
 
+// renderScanProgress renders the counters of the running scan
+func (m Model) renderScanProgress() string {
+	s := m.activeScan
+	if s == nil {
+		return ""
+	}
+	elapsed := time.Since(s.started).Truncate(100 * time.Millisecond)
+	return hintStyle.Render(fmt.Sprintf("📂 %d dirs walked · %d repos found · %d statuses done · %s · esc to cancel",
+		s.progress.Dirs(), s.progress.Found(), s.progress.Done(), elapsed))
+}
+
 // renderLegend renders a compact single-line legend (Tuimorphic style)
 func (m Model) renderLegend() string {
 	dirty := dirtyDotStyle.Render("●") + legendStyle.Render(" dirty")
 	clean := cleanDotStyle.Render("○") + legendStyle.Render(" clean")
+	unpublished := unpublishedDotStyle.Render("⇡") + legendStyle.Render(" no upstream")
+	errored := errorDotStyle.Render("✗") + legendStyle.Render(" error")
+	bare := bareDotStyle.Render("🗄") + legendStyle.Render(" bare")
 	editor := legendStyle.Render(fmt.Sprintf("  Editor: %s", m.cfg.Editor))
 
-	return legendStyle.Render(dirty + "  " + clean + editor)
+	return legendStyle.Render(dirty + "  " + clean + "  " + unpublished + "  " + errored + "  " + bare + editor)
 }
 
 // renderHelp renders a Tuimorphic keybindings bar with box-drawing separators
@@ -297,6 +363,22 @@ func (m Model) renderHelp() string {
 			keyBinding("enter", "switch"),
 			keyBinding("esc", "cancel"),
 		}
+	} else if m.activePanel == PanelDetail && m.detailFocus {
+		// Detail pane file selection help
+		items = []string{
+			keyBinding("↑↓", "file"),
+			keyBinding("enter", "diff"),
+			keyBinding("tab", "back"),
+			keyBinding("q", "quit"),
+		}
+	} else if m.activePanel == PanelDetail {
+		items = []string{
+			keyBinding("↑↓", "nav"),
+			keyBinding("tab", "files"),
+			keyBinding("esc", "close"),
+			keyBinding("i", "detail"),
+			keyBinding("q", "quit"),
+		}
 	} else if m.activePanel != PanelNone {
 		// Panel active help
 		items = []string{
@@ -305,6 +387,9 @@ func (m Model) renderHelp() string {
 			keyBinding("g", "grass"),
 			keyBinding("d", "disk"),
 			keyBinding("t", "time"),
+			keyBinding("m", "subs"),
+			keyBinding("p", "unpushed"),
+			keyBinding("i", "detail"),
 			keyBinding("q", "quit"),
 		}
 	} else {
@@ -320,6 +405,11 @@ func (m Model) renderHelp() string {
 			keyBinding("g", "grass"),
 			keyBinding("d", "disk"),
 			keyBinding("t", "time"),
+			keyBinding("m", "subs"),
+			keyBinding("p", "unpushed"),
+			keyBinding("i", "detail"),
+			keyBinding("u", "refresh"),
+			keyBinding("a", "auto"),
 			keyBinding("r", "rescan"),
 			keyBinding("q", "quit"),
 		}
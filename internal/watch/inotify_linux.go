@@ -0,0 +1,207 @@
+package watch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// inotifyMask selects the events that can change what git status
+// reports. Plain reads do not trigger any. git status itself rewrites the
+// index unless run with GIT_OPTIONAL_LOCKS=0, as it is in hardened mode;
+// the watcher drops those events when the index content is unchanged.
+const inotifyMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_CLOSE_WRITE |
+	unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_ATTRIB | unix.IN_ONLYDIR
+
+// inotifyWatch is one watched directory, which linked worktrees of the
+// same repo can share
+type inotifyWatch struct {
+	path  string
+	repos map[string]bool
+}
+
+// inotify watches directories with a single inotify instance
+type inotify struct {
+	fd   int // Kept apart since file.Fd() would make reads blocking
+	file *os.File
+	out  chan event
+	done chan struct{}
+
+	mu      sync.Mutex
+	byWD    map[int32]*inotifyWatch
+	byPath  map[string]int32
+	forRepo map[string][]string // Directories watched for each repo
+}
+
+func newNotifier() (notifier, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	// A non-blocking fd goes through the runtime poller, so closing the
+	// file interrupts a pending read
+	n := &inotify{
+		fd:      fd,
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		out:     make(chan event, 64),
+		done:    make(chan struct{}),
+		byWD:    make(map[int32]*inotifyWatch),
+		byPath:  make(map[string]int32),
+		forRepo: make(map[string][]string),
+	}
+	go n.read()
+	return n, nil
+}
+
+func (n *inotify) events() <-chan event { return n.out }
+
+func (n *inotify) close() error {
+	close(n.done)
+	return n.file.Close()
+}
+
+// watch replaces the directories watched for a repo. It fails with
+// ENOSPC once the user's inotify watch limit is reached; directories that
+// don't exist are skipped.
+func (n *inotify) watch(repo string, dirs []string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	want := make(map[string]bool, len(dirs))
+	for _, dir := range dirs {
+		want[dir] = true
+	}
+	for _, dir := range n.forRepo[repo] {
+		if !want[dir] {
+			n.release(repo, dir)
+		}
+	}
+
+	var kept []string
+	var failed error
+	for dir := range want {
+		if wd, ok := n.byPath[dir]; ok {
+			n.byWD[wd].repos[repo] = true
+			kept = append(kept, dir)
+			continue
+		}
+		wd, err := unix.InotifyAddWatch(n.fd, dir, inotifyMask)
+		if err != nil {
+			if errors.Is(err, unix.ENOSPC) || errors.Is(err, unix.ENOMEM) {
+				failed = err
+			}
+			continue
+		}
+		if w, ok := n.byWD[int32(wd)]; ok {
+			// The same directory under another path, e.g. via a symlink
+			w.repos[repo] = true
+		} else {
+			n.byWD[int32(wd)] = &inotifyWatch{path: dir, repos: map[string]bool{repo: true}}
+			n.byPath[dir] = int32(wd)
+		}
+		kept = append(kept, dir)
+	}
+	n.forRepo[repo] = kept
+	return failed
+}
+
+// unwatch stops watching the directories of a repo
+func (n *inotify) unwatch(repo string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, dir := range n.forRepo[repo] {
+		n.release(repo, dir)
+	}
+	delete(n.forRepo, repo)
+}
+
+// release drops a repo from a directory's watch, removing the watch once
+// no repo needs it. n.mu must be held.
+func (n *inotify) release(repo, dir string) {
+	wd, ok := n.byPath[dir]
+	if !ok {
+		return
+	}
+	w := n.byWD[wd]
+	delete(w.repos, repo)
+	if len(w.repos) == 0 {
+		_, _ = unix.InotifyRmWatch(n.fd, uint32(wd))
+		delete(n.byWD, wd)
+		delete(n.byPath, dir)
+	}
+}
+
+// read turns inotify events into the paths of the repos they belong to
+func (n *inotify) read() {
+	defer close(n.out)
+	buf := make([]byte, 64*1024)
+	for {
+		count, err := n.file.Read(buf)
+		if err != nil {
+			return
+		}
+
+		var events []event
+		for off := 0; off+unix.SizeofInotifyEvent <= count; {
+			ev := (*unix.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			nameBytes := buf[off+unix.SizeofInotifyEvent : off+unix.SizeofInotifyEvent+int(ev.Len)]
+			name := strings.TrimRight(string(nameBytes), "\x00")
+			off += unix.SizeofInotifyEvent + int(ev.Len)
+
+			events = append(events, n.eventsFor(ev.Wd, ev.Mask, name)...)
+		}
+		for _, ev := range events {
+			select {
+			case n.out <- ev:
+			case <-n.done:
+				return
+			}
+		}
+	}
+}
+
+// eventsFor turns an inotify event into one event for each repo it
+// concerns
+func (n *inotify) eventsFor(wd int32, mask uint32, name string) []event {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if mask&unix.IN_Q_OVERFLOW != 0 {
+		// Events were lost: every repo may have changed
+		events := make([]event, 0, len(n.forRepo))
+		for repo := range n.forRepo {
+			events = append(events, event{repo: repo})
+		}
+		return events
+	}
+
+	w, ok := n.byWD[wd]
+	if !ok {
+		return nil
+	}
+	if mask&unix.IN_IGNORED != 0 {
+		// The directory was deleted or unmounted
+		delete(n.byWD, wd)
+		delete(n.byPath, w.path)
+	}
+	// Lock files come and go around every write git makes; the rename
+	// that ends the write is reported under the real name
+	if strings.HasSuffix(name, ".lock") {
+		return nil
+	}
+
+	path := w.path
+	if name != "" {
+		path = filepath.Join(w.path, name)
+	}
+	events := make([]event, 0, len(w.repos))
+	for repo := range w.repos {
+		events = append(events, event{repo: repo, path: path})
+	}
+	return events
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package watch
+
+import "errors"
+
+// newNotifier reports that file notifications are unavailable, so every
+// repo is polled
+func newNotifier() (notifier, error) {
+	return nil, errors.New("file notifications are only supported on Linux")
+}
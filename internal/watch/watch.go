@@ -0,0 +1,311 @@
+// Package watch reports repositories whose git state or worktree changed.
+// On Linux it uses inotify on each repo's git directory, refs and tracked
+// worktree directories; elsewhere, or when inotify runs out of watches,
+// it polls.
+package watch
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Bharath-code/git-scope/internal/gitstatus"
+)
+
+// Defaults for Options
+const (
+	DefaultDebounce     = 500 * time.Millisecond
+	DefaultPollInterval = 2 * time.Second
+)
+
+// Backend names reported by Watcher.Backend
+const (
+	BackendInotify = "inotify"
+	BackendPolling = "polling"
+)
+
+// Options configures a Watcher
+type Options struct {
+	// Debounce is how long a repo must be quiet before its change is
+	// reported, so a commit or checkout is reported once. Zero means
+	// DefaultDebounce.
+	Debounce time.Duration
+
+	// PollInterval is how often polled repos are checked. Zero means
+	// DefaultPollInterval.
+	PollInterval time.Duration
+}
+
+// Watcher watches a set of repos and delivers the paths of those that
+// changed on Changes, in batches
+type Watcher struct {
+	opts    Options
+	notify  notifier // Nil if file notifications are unavailable
+	changes chan []string
+	done    chan struct{}
+	stop    sync.Once
+
+	// Repos handed over by SetRepos, picked up by the loop
+	mu      sync.Mutex
+	next    []string
+	updated chan struct{}
+}
+
+// notifier is a source of file notifications. watch sets the directories
+// watched for a repo, replacing any it had before.
+type notifier interface {
+	watch(repo string, dirs []string) error
+	unwatch(repo string)
+	events() <-chan event
+	close() error
+}
+
+// event tells that something changed in a repo. Path is the file that
+// changed, or empty if it is unknown.
+type event struct {
+	repo string
+	path string
+}
+
+// New starts a watcher with no repos
+func New(opts Options) *Watcher {
+	n, err := newNotifier()
+	if err != nil {
+		n = nil
+	}
+	return newWatcher(opts, n)
+}
+
+// newWatcher starts a watcher using n, or polling only if n is nil
+func newWatcher(opts Options, n notifier) *Watcher {
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultDebounce
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+
+	w := &Watcher{
+		opts:    opts,
+		changes: make(chan []string),
+		done:    make(chan struct{}),
+		updated: make(chan struct{}, 1),
+		notify:  n,
+	}
+	go w.loop()
+	return w
+}
+
+// Backend returns how changes are detected: BackendInotify, or
+// BackendPolling if file notifications are unavailable. Repos beyond the
+// inotify watch limit are polled either way.
+func (w *Watcher) Backend() string {
+	if w.notify == nil {
+		return BackendPolling
+	}
+	return BackendInotify
+}
+
+// Changes delivers the paths of changed repos. It is closed by Close.
+func (w *Watcher) Changes() <-chan []string {
+	return w.changes
+}
+
+// SetRepos replaces the watched repos. It does not block.
+func (w *Watcher) SetRepos(paths []string) {
+	w.mu.Lock()
+	w.next = append([]string(nil), paths...)
+	w.mu.Unlock()
+	select {
+	case w.updated <- struct{}{}:
+	default:
+	}
+}
+
+// Close stops watching and closes Changes
+func (w *Watcher) Close() {
+	w.stop.Do(func() { close(w.done) })
+}
+
+// loop owns the watched repos and the pending changes
+func (w *Watcher) loop() {
+	defer close(w.changes)
+
+	var events <-chan event
+	if w.notify != nil {
+		defer w.notify.close()
+		events = w.notify.events()
+	}
+
+	watched := make(map[string]bool)
+	polled := make(map[string]string)      // Repo path to its last poll state
+	indexes := make(map[string]indexState) // Repo path to its index, when notified
+	pending := make(map[string]bool)
+	var firstPending time.Time
+
+	quiet := time.NewTimer(time.Hour)
+	quiet.Stop()
+	poll := time.NewTicker(w.opts.PollInterval)
+	defer poll.Stop()
+
+	// watchRepo (re)computes the directories of a repo and watches them,
+	// falling back to polling when the notifier cannot take them
+	watchRepo := func(repo string) {
+		if w.notify != nil {
+			layout, err := gitstatus.ResolveLayout(repo)
+			dirs := []string{repo}
+			if err == nil {
+				dirs = repoDirs(repo, layout)
+				index := filepath.Join(layout.GitDir, "index")
+				indexes[repo] = indexState{path: index, key: gitstatus.IndexKey(layout.GitDir)}
+			}
+			if err := w.notify.watch(repo, dirs); err == nil {
+				delete(polled, repo)
+				return
+			}
+			w.notify.unwatch(repo)
+			delete(indexes, repo)
+		}
+		if _, ok := polled[repo]; !ok {
+			polled[repo] = pollState(repo)
+		}
+	}
+
+	changed := func(repo string) {
+		if !watched[repo] {
+			return
+		}
+		if len(pending) == 0 {
+			firstPending = time.Now()
+		}
+		pending[repo] = true
+		// Report at the latest after ten quiet periods, so a repo that
+		// keeps changing is still refreshed now and then
+		wait := w.opts.Debounce
+		if deadline := firstPending.Add(10 * w.opts.Debounce); time.Now().Add(wait).After(deadline) {
+			wait = time.Until(deadline)
+		}
+		quiet.Reset(wait)
+	}
+
+	for {
+		select {
+		case <-w.done:
+			return
+
+		case <-w.updated:
+			w.mu.Lock()
+			next := w.next
+			w.mu.Unlock()
+
+			keep := make(map[string]bool, len(next))
+			for _, repo := range next {
+				keep[repo] = true
+				if !watched[repo] {
+					watched[repo] = true
+					watchRepo(repo)
+				}
+			}
+			for repo := range watched {
+				if !keep[repo] {
+					delete(watched, repo)
+					delete(polled, repo)
+					delete(indexes, repo)
+					delete(pending, repo)
+					if w.notify != nil {
+						w.notify.unwatch(repo)
+					}
+				}
+			}
+
+		case ev := <-events:
+			// git status rewrites the index just to refresh the stat
+			// data cached in it, unless run with GIT_OPTIONAL_LOCKS=0.
+			// That is usually our own refresh, not a change.
+			if index, ok := indexes[ev.repo]; ok && ev.path == index.path {
+				key := gitstatus.IndexKey(filepath.Dir(index.path))
+				if key == index.key {
+					continue
+				}
+				indexes[ev.repo] = indexState{path: index.path, key: key}
+			}
+			changed(ev.repo)
+
+		case <-poll.C:
+			for repo, state := range polled {
+				if now := pollState(repo); now != state {
+					polled[repo] = now
+					changed(repo)
+				}
+			}
+
+		case <-quiet.C:
+			if len(pending) == 0 {
+				continue
+			}
+			batch := make([]string, 0, len(pending))
+			for repo := range pending {
+				batch = append(batch, repo)
+				// New branches or tracked directories need new watches
+				watchRepo(repo)
+			}
+			sort.Strings(batch)
+			pending = make(map[string]bool)
+
+			select {
+			case w.changes <- batch:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+// indexState is the index file of a notified repo with its IndexKey
+type indexState struct {
+	path string
+	key  string
+}
+
+// repoDirs returns the directories to watch for a repo: its git
+// directories (HEAD, index, packed-refs, in-progress operations), every
+// directory below refs, and the worktree directories holding tracked
+// files. Untracked and ignored trees such as node_modules are left out;
+// new files in them do not change what git status reports.
+func repoDirs(repoPath string, layout gitstatus.Layout) []string {
+	dirs := []string{layout.GitDir}
+	if layout.CommonDir != layout.GitDir {
+		dirs = append(dirs, layout.CommonDir)
+	}
+	_ = filepath.WalkDir(filepath.Join(layout.CommonDir, "refs"), func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+
+	tracked, err := gitstatus.TrackedDirs(layout)
+	if err != nil {
+		tracked = []string{""}
+	}
+	for _, dir := range tracked {
+		dirs = append(dirs, filepath.Join(repoPath, filepath.FromSlash(dir)))
+	}
+	return dirs
+}
+
+// pollState summarizes what a poll compares: the repo's git content
+// fingerprint, which covers the stat data of its tracked files and
+// directories. That catches files saved in place as well as files
+// created, deleted or saved by renaming over them. The content
+// fingerprint ignores index rewrites by git status, as the notified
+// repos do.
+func pollState(repoPath string) string {
+	layout, err := gitstatus.ResolveLayout(repoPath)
+	if err != nil {
+		return ""
+	}
+	return gitstatus.ContentFingerprint(repoPath, layout)
+}
@@ -0,0 +1,162 @@
+package watch
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testOptions keep the tests fast; the quiet period still has to cover
+// a few polls
+var testOptions = Options{Debounce: 100 * time.Millisecond, PollInterval: 20 * time.Millisecond}
+
+// settle is how long the tests wait for something that should not happen
+const settle = 500 * time.Millisecond
+
+// newTestRepo creates a repo with one commit. Its tracked file is dated
+// back, so the next git status has stat data to refresh in the index.
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fixture relies on POSIX directory modification times")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, "tracked.txt"))
+	gitRun(t, repo, "init", "-q")
+	gitRun(t, repo, "add", "tracked.txt")
+	gitRun(t, repo, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial")
+
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(repo, "tracked.txt"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+// gitRun runs git the way an unhardened refresh does, with optional locks
+func gitRun(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, "GIT_") {
+			cmd.Env = append(cmd.Env, kv)
+		}
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte("x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// backends returns the watchers to test: polling everywhere, and inotify
+// where file notifications are available
+func backends(t *testing.T) map[string]func() *Watcher {
+	b := map[string]func() *Watcher{
+		BackendPolling: func() *Watcher { return newWatcher(testOptions, nil) },
+	}
+	if n, err := newNotifier(); err == nil {
+		n.close()
+		b[BackendInotify] = func() *Watcher {
+			n, err := newNotifier()
+			if err != nil {
+				t.Fatal(err)
+			}
+			return newWatcher(testOptions, n)
+		}
+	}
+	return b
+}
+
+// expectBatch waits for the next batch of changes
+func expectBatch(t *testing.T, w *Watcher, want []string) {
+	t.Helper()
+	select {
+	case got := <-w.Changes():
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("batch = %v, want %v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no batch, want %v", want)
+	}
+}
+
+// expectQuiet checks that no batch arrives for a while
+func expectQuiet(t *testing.T, w *Watcher, why string) {
+	t.Helper()
+	select {
+	case got := <-w.Changes():
+		t.Fatalf("%s: unexpected batch %v", why, got)
+	case <-time.After(settle):
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	for name, start := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			repo := newTestRepo(t)
+			w := start()
+			defer w.Close()
+			if w.Backend() != name {
+				t.Fatalf("backend = %s, want %s", w.Backend(), name)
+			}
+
+			w.SetRepos([]string{repo})
+			expectQuiet(t, w, "repo just added")
+
+			// Two files in quick succession are reported once
+			writeFile(t, filepath.Join(repo, "a.txt"))
+			writeFile(t, filepath.Join(repo, "b.txt"))
+			expectBatch(t, w, []string{repo})
+			expectQuiet(t, w, "after the batch")
+
+			// A refresh without GIT_OPTIONAL_LOCKS=0 rewrites the index
+			// to record fresh stat data, which is not a change
+			index := filepath.Join(repo, ".git", "index")
+			before, err := os.Stat(index)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gitRun(t, repo, "status", "--porcelain")
+			after, err := os.Stat(index)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if after.ModTime().Equal(before.ModTime()) {
+				t.Fatal("git status did not rewrite the index, the fixture is broken")
+			}
+			expectQuiet(t, w, "index rewritten by git status")
+
+			// Staging does change the index
+			gitRun(t, repo, "add", "a.txt")
+			expectBatch(t, w, []string{repo})
+
+			// Saving a tracked file in place changes no directory
+			if err := os.WriteFile(filepath.Join(repo, "tracked.txt"), []byte("edited\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			expectBatch(t, w, []string{repo})
+
+			// A removed repo is no longer reported
+			w.SetRepos(nil)
+			expectQuiet(t, w, "repo just removed")
+			writeFile(t, filepath.Join(repo, "c.txt"))
+			expectQuiet(t, w, "change in a removed repo")
+		})
+	}
+}